@@ -1,34 +1,75 @@
 package logger
 
 import (
-	"sync"
+	"io"
+	"time"
 )
 
 const (
-	defaultLogLevel         = "debug" //  "debug | info | warn | error"
+	defaultLogLevel         = "debug" //  "trace | debug | info | warn | error"
 	defaultTeamsLogLevel    = "warn"
 	defaultSlackAPILogLevel = "warn"
 	defaultLogChannelSize   = 2000
+	defaultSyslogLogLevel   = "info"
+	defaultWebhookLogLevel  = "warn"
 )
 
-var logsChannel chan [][]byte
-var logsWaitGroup = new(sync.WaitGroup)
-var logsDone chan bool
-
 type LogConfig struct {
-	EnvPrefix   string
-	Formatter   string // "text" | "json"
-	LogLevel    string //  "debug | info | warn | error"
-	LogChanSize int
-	TeamsLogCfg TeamsLogCfg
-	SlackAPICfg SlackAPICfg
-	LogChanCfg  LogChanCfg
+	EnvPrefix string
+	Formatter string // "text" | "json"
+	LogLevel  string //  "trace | debug | info | warn | error"
+	// ComponentLevels sets levels of named Loggers (see Named), e.g. "db=debug,http.client=warn,*=info"
+	// A name matches itself and its dotted children; "*" overrides LogLevel for everything else
+	ComponentLevels string
+	LogChanSize     int    // size of the async queue
+	AsyncPolicy     string // async queue overflow policy "block | drop-newest | drop-oldest | spill-to-sync"
+	AsyncCaller     bool   // add the "location" (file:line) of the LogAsync call to async entries
+	TeamsLogCfg     TeamsLogCfg
+	SlackAPICfg     SlackAPICfg
+	LogChanCfg      LogChanCfg
+	FileLogCfg      FileLogCfg
+	SyslogCfg       SyslogCfg
+	JournaldCfg     JournaldCfg
+	GELFCfg         GELFCfg
+	LokiCfg         LokiCfg
+	OTLPCfg         OTLPCfg
+	ElasticCfg      ElasticCfg
+	FluentCfg       FluentCfg
+	// Webhooks send entries to HTTP endpoints with bodies rendered from templates
+	Webhooks []WebhookCfg
+	// Outputs, when given, replace the single console output: each entry is written to every
+	// output whose level accepts it, formatted by the output's own formatter
+	Outputs []OutputCfg
+}
+
+// OutputCfg configures one of several outputs (see LogConfig.Outputs)
+type OutputCfg struct {
+	Name      string    // identifies the output in Reconfigure changes and the admin handler; defaults to its position
+	Writer    io.Writer // destination, e.g. a net.Conn; if nil Target is used
+	Target    string    // "stdout" | "stderr" | a file path (appended to)
+	Formatter string    // "text" | "json" | "logfmt"
+	LogLevel  string    // "trace | debug | info | warn | error | fatal"; if empty the Logger's levels apply
 }
 
 type TeamsLogCfg struct {
 	Enabled  bool
 	Endpoint string // Endpoint for your Teams hook
 	LogLevel string //  "debug | info | warn | error | fatal"
+	// CardFormat is "message_card" (default) for Office 365 connectors
+	// or "adaptive_card" for Workflows (Power Automate) webhooks
+	CardFormat string
+	Actions    []TeamsAction // buttons of adaptive cards
+	Timeout    time.Duration // of each post to Teams; defaults to 10s
+	MaxRetries int           // of failed posts, with exponential backoff; defaults to 5, -1 disables retries
+	// PinnedFields are shown first on the cards, in this order; the other fields follow sorted by name
+	PinnedFields   []string
+	ExcludedFields []string // fields left off the cards, e.g. "function"
+}
+
+// TeamsAction is a button of a Teams adaptive card opening URL
+type TeamsAction struct {
+	Title string
+	URL   string
 }
 
 type SlackAPICfg struct {
@@ -46,3 +87,116 @@ type LogChanCfg struct {
 	Ch       chan string // caller-provided channel to receive log messages
 	LogLevel string     // "debug | info | warn | error | fatal"
 }
+
+// FileLogCfg configures logging to a file which is rotated by size and/or daily.
+// Rotated files are named like app-2024-05-11T19-30-09.000.log (.gz when compressed)
+type FileLogCfg struct {
+	Enabled     bool
+	Path        string // e.g. "/var/log/myapp/myapp.log"
+	LogLevel    string // "trace | debug | info | warn | error | fatal"
+	Formatter   string // "text" | "json"; defaults to LogConfig.Formatter
+	MaxSizeMB   int    // rotate when the file would exceed this size; 0 disables size rotation
+	Daily       bool   // rotate when the day changes
+	Compress    bool   // gzip rotated files
+	MaxAgeDays  int    // remove rotated files older than this; 0 keeps them
+	MaxBackups  int    // keep at most this many rotated files; 0 keeps them all
+	ReopenOnHUP bool   // reopen Path on SIGHUP, for use with an external logrotate
+}
+
+// SyslogCfg configures sending logs to a syslog server (e.g. rsyslog) as RFC 5424 messages
+type SyslogCfg struct {
+	Enabled  bool
+	Network  string // "udp" (default) | "tcp" | "unix"
+	Address  string // host:port, or the socket path for "unix"; defaults to "localhost:514" or "/dev/log"
+	Facility string // "user" (default) | "daemon" | "local0" ... "local7" etc.
+	AppName  string // defaults to the program name
+	LogLevel string // "trace | debug | info | warn | error | fatal"
+	SDID     string // SD-ID of the structured data holding the fields; defaults to "fields@32473"
+}
+
+// JournaldCfg configures writing to systemd-journald over its native protocol,
+// keeping each field as a journal field
+type JournaldCfg struct {
+	Enabled          bool
+	SocketPath       string // defaults to "/run/systemd/journal/socket"
+	SyslogIdentifier string // defaults to the program name
+	LogLevel         string // "trace | debug | info | warn | error | fatal"
+}
+
+// GELFCfg configures sending logs to Graylog as GELF messages
+type GELFCfg struct {
+	Enabled     bool
+	Network     string // "udp" (default) | "tcp"
+	Address     string // host:port of the GELF input; defaults to "localhost:12201"
+	Host        string // the "host" of messages; defaults to the hostname
+	Compression string // UDP only: "gzip" (default) | "zlib" | "none"
+	ChunkSize   int    // UDP only: maximum datagram size, at least 128; defaults to 1420
+	LogLevel    string // "trace | debug | info | warn | error | fatal"
+}
+
+// LokiCfg configures pushing logs to Grafana Loki in batches
+type LokiCfg struct {
+	Enabled     bool
+	URL         string            // Loki address (http://loki:3100) or the full push URL
+	Labels      map[string]string // labels of all streams, e.g. {"service": "billing", "env": "prod"}
+	LabelFields []string          // fields whose values become stream labels; defaults to ["level"]
+	TenantID    string            // X-Scope-OrgID for multi-tenant Loki
+	Username    string            // basic auth, when set
+	Password    string
+	BatchSize   int           // entries per push; defaults to 500
+	BatchWait   time.Duration // longest an entry waits for its batch to fill; defaults to 1s
+	LogLevel    string        // "trace | debug | info | warn | error | fatal"
+}
+
+// OTLPCfg configures exporting logs to an OpenTelemetry collector over OTLP/HTTP.
+// Fields "trace_id" and "span_id" (hex) set the trace context of records
+type OTLPCfg struct {
+	Enabled            bool
+	Endpoint           string            // collector address or full logs URL; defaults to "http://localhost:4318/v1/logs"
+	Protocol           string            // "http/json" (default) | "http/protobuf"
+	Headers            map[string]string // e.g. {"Authorization": "Bearer ..."}
+	ServiceName        string            // the "service.name" resource attribute
+	ResourceAttributes map[string]string // e.g. {"deployment.environment": "prod"}
+	Gzip               bool              // compress requests
+	BatchSize          int               // records per request; defaults to 512
+	BatchWait          time.Duration     // longest a record waits for its batch to fill; defaults to 1s
+	LogLevel           string            // "trace | debug | info | warn | error | fatal"
+}
+
+// ElasticCfg configures indexing logs into Elasticsearch or OpenSearch with _bulk requests
+type ElasticCfg struct {
+	Enabled   bool
+	URL       string // e.g. "https://search.internal:9200"
+	Index     string // index pattern with a Go date layout in braces; defaults to "logs-{2006.01.02}"
+	Username  string // basic auth, when set
+	Password  string
+	APIKey    string        // the encoded API key; takes precedence over basic auth
+	BatchSize int           // documents per request; defaults to 500
+	BatchWait time.Duration // longest an entry waits for its batch to fill; defaults to 1s
+	LogLevel  string        // "trace | debug | info | warn | error | fatal"
+}
+
+// FluentCfg configures sending logs to Fluentd or Fluent Bit over the forward protocol
+type FluentCfg struct {
+	Enabled    bool
+	Network    string        // "tcp" (default) | "unix"
+	Address    string        // host:port, or the socket path for "unix"; defaults to "localhost:24224"
+	Tag        string        // e.g. "billing" or "billing.{level}"; defaults to the program name
+	RequireAck bool          // have the server acknowledge each message, resending if it doesn't
+	BatchSize  int           // entries per message; defaults to 500
+	BatchWait  time.Duration // longest an entry waits for its batch to fill; defaults to 1s
+	LogLevel   string        // "trace | debug | info | warn | error | fatal"
+}
+
+// WebhookCfg configures sending logs to an HTTP endpoint (see LogConfig.Webhooks)
+type WebhookCfg struct {
+	Name            string            // identifies the webhook in Reconfigure changes and the admin handler; defaults to its position
+	URL             string            // endpoint of the webhook
+	Method          string            // defaults to "POST"
+	Template        string            // text/template of the body over webhook_log.TemplateData; defaults to JSON of it
+	ContentType     string            // defaults to "application/json"
+	Headers         map[string]string // e.g. {"Authorization": "Bearer ..."}
+	Secret          string            // signs bodies with HMAC-SHA256 when set
+	SignatureHeader string            // header of the signature; defaults to "X-Signature-256"
+	LogLevel        string            // "trace | debug | info | warn | error | fatal"
+}
@@ -7,9 +7,7 @@ toolchain go1.24.6
 require (
 	github.com/rohanthewiz/serr v1.2.20
 	github.com/sirupsen/logrus v1.9.3
+	golang.org/x/sys v0.39.0
 )
 
-require (
-	github.com/stretchr/testify v1.11.1 // indirect
-	golang.org/x/sys v0.39.0 // indirect
-)
+require github.com/stretchr/testify v1.11.1 // indirect
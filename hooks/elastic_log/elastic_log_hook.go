@@ -0,0 +1,254 @@
+package elastic_log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rohanthewiz/logger/internal/batch"
+	"github.com/rohanthewiz/logger/internal/hookctl"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultIndex      = "logs-{2006.01.02}"
+	DefaultBatchSize  = 500
+	DefaultBatchWait  = time.Second
+	defaultMaxRetries = 5
+	defaultMinBackoff = 500 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+	closeTimeout      = 5 * time.Second
+)
+
+// indexDate matches the date layout in an index pattern, e.g. {2006.01.02}
+var indexDate = regexp.MustCompile(`\{([^}]*)\}`)
+
+// ElasticHook is a logrus hook that indexes entries into Elasticsearch or OpenSearch with _bulk requests.
+// Entries are sent in batches to the index named by Index, where a Go time layout in braces is
+// replaced by the entry's UTC date, e.g. "logs-{2006.01.02}" gives logs-2024.05.11.
+// Documents the bulk response reports as rejected with 429 or 5xx are retried with backoff,
+// others (e.g. mapping errors) are dropped
+type ElasticHook struct {
+	URL            string // e.g. https://search.internal:9200
+	Index          string // index pattern
+	Username       string // basic auth, when set
+	Password       string
+	APIKey         string        // the encoded API key, sent as "Authorization: ApiKey ..."
+	BatchSize      int           // documents per request
+	BatchWait      time.Duration // longest an entry waits for its batch to fill
+	Backoff        batch.Backoff // retries of failed requests and documents
+	Client         *http.Client
+	AcceptedLevels []logrus.Level // levels that trigger this hook
+	Disabled       bool           // allows the hook to be temporarily silenced
+
+	ctl         hookctl.Control // runtime changes of Disabled and AcceptedLevels
+	batcherOnce sync.Once
+	batcher     *batch.Batcher[document] // created with the first entry, from BatchSize and BatchWait
+}
+
+// document is an entry ready to be indexed
+type document struct {
+	index  string
+	source []byte
+}
+
+// NewElasticHook creates an ElasticHook. Call Close to send remaining entries when done
+func NewElasticHook(url, index string, acceptedLevels []logrus.Level) *ElasticHook {
+	if index == "" {
+		index = DefaultIndex
+	}
+	return &ElasticHook{
+		URL:            strings.TrimSuffix(url, "/"),
+		Index:          index,
+		BatchSize:      DefaultBatchSize,
+		BatchWait:      DefaultBatchWait,
+		Backoff:        batch.Backoff{MaxRetries: defaultMaxRetries, Min: defaultMinBackoff, Max: defaultMaxBackoff},
+		Client:         &http.Client{Timeout: 30 * time.Second},
+		AcceptedLevels: acceptedLevels,
+	}
+}
+
+// Levels returns the set of log levels this hook responds to.
+// Required by the logrus.Hook interface.
+func (h *ElasticHook) Levels() []logrus.Level {
+	return h.ctl.Levels(h.AcceptedLevels)
+}
+
+// IsEnabled reports whether the hook fires
+func (h *ElasticHook) IsEnabled() bool {
+	return h.ctl.Enabled(!h.Disabled)
+}
+
+// SetEnabled enables or disables the hook, safely while it fires
+func (h *ElasticHook) SetEnabled(enabled bool) {
+	h.ctl.SetEnabled(enabled)
+}
+
+// SetLevels changes the levels the hook accepts, safely while it fires
+func (h *ElasticHook) SetLevels(levels []logrus.Level) {
+	h.ctl.SetLevels(levels)
+}
+
+// Fire queues the log entry for the next bulk request.
+// Required by the logrus.Hook interface.
+func (h *ElasticHook) Fire(entry *logrus.Entry) error {
+	if !h.IsEnabled() {
+		return nil
+	}
+
+	doc := make(map[string]any, len(entry.Data)+3)
+	for k, v := range entry.Data {
+		if err, ok := v.(error); ok {
+			v = err.Error() // errors marshal as {}
+		}
+		doc[k] = v
+	}
+	for k, v := range map[string]any{
+		"@timestamp": entry.Time.Format(time.RFC3339Nano),
+		"message":    entry.Message,
+		"level":      entry.Level.String(),
+	} {
+		if clash, ok := doc[k]; ok {
+			doc["fields."+k] = clash
+		}
+		doc[k] = v
+	}
+
+	source, err := json.Marshal(doc)
+	if err != nil {
+		fmt.Println("elastic_log: unable to encode log entry:", err)
+		return nil
+	}
+
+	if !h.batch().Add(document{index: h.IndexName(entry.Time), source: source}) {
+		fmt.Println("elastic_log: queue full, dropping log entry")
+	}
+	return nil
+}
+
+// IndexName returns the index for an entry logged at t
+func (h *ElasticHook) IndexName(t time.Time) string {
+	return indexDate.ReplaceAllStringFunc(h.Index, func(m string) string {
+		return t.UTC().Format(m[1 : len(m)-1])
+	})
+}
+
+// Flush sends queued entries, waiting for the request to complete or ctx to be done
+func (h *ElasticHook) Flush(ctx context.Context) error {
+	return h.batch().Flush(ctx)
+}
+
+// Close sends queued entries and stops the sender
+func (h *ElasticHook) Close() error {
+	return h.batch().Close(closeTimeout)
+}
+
+func (h *ElasticHook) batch() *batch.Batcher[document] {
+	h.batcherOnce.Do(func() { h.batcher = batch.New(h.BatchSize, h.BatchWait, h.index) })
+	return h.batcher
+}
+
+// index sends the documents, retrying the request or the documents rejected as retryable
+func (h *ElasticHook) index(docs []document) {
+	pending := docs
+
+	err := h.Backoff.Retry(h.batch(), func() (time.Duration, error) {
+		retry, retryAfter, err := h.bulk(pending)
+		if err != nil {
+			return retryAfter, err
+		}
+		if len(retry) > 0 {
+			pending = retry
+			return 0, fmt.Errorf("%d documents rejected", len(retry))
+		}
+		return 0, nil
+	})
+	if err != nil {
+		fmt.Printf("elastic_log: dropping %d documents: %v\n", len(pending), err)
+	}
+}
+
+// bulkResponse is the part of a _bulk response we need
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// bulk sends a _bulk request, returning the documents to retry.
+// A negative retryAfter means the request must not be retried
+func (h *ElasticHook) bulk(docs []document) (retry []document, retryAfter time.Duration, err error) {
+	var body bytes.Buffer
+	for _, doc := range docs {
+		action, _ := json.Marshal(map[string]any{"index": map[string]string{"_index": doc.index}})
+		body.Write(action)
+		body.WriteByte('\n')
+		body.Write(doc.source)
+		body.WriteByte('\n')
+	}
+
+	req, err := http.NewRequest(http.MethodPost, h.URL+"/_bulk", &body)
+	if err != nil {
+		return nil, -1, err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	switch {
+	case h.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+h.APIKey)
+	case h.Username != "":
+		req.SetBasicAuth(h.Username, h.Password)
+	}
+
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		err = fmt.Errorf("bulk request returned %s: %s", resp.Status, respBody)
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			return nil, batch.RetryAfter(resp.Header.Get("Retry-After")), err
+		}
+		return nil, -1, err
+	}
+
+	var br bulkResponse
+	if err = json.NewDecoder(resp.Body).Decode(&br); err != nil {
+		return nil, -1, fmt.Errorf("unable to decode bulk response: %w", err)
+	}
+	if !br.Errors {
+		return nil, 0, nil
+	}
+
+	// Items are in the order of the request
+	for i, item := range br.Items {
+		if i >= len(docs) {
+			break
+		}
+		for _, result := range item { // keyed by the action
+			switch {
+			case result.Status < 300:
+			case result.Status == http.StatusTooManyRequests || result.Status >= 500:
+				retry = append(retry, docs[i])
+			default:
+				fmt.Printf("elastic_log: document rejected by %s (%d %s): %s\n",
+					docs[i].index, result.Status, result.Error.Type, result.Error.Reason)
+			}
+		}
+	}
+	return retry, 0, nil
+}
@@ -0,0 +1,90 @@
+package file_log
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/rohanthewiz/logger/internal/hookctl"
+	"github.com/sirupsen/logrus"
+)
+
+// FileLogHook is a logrus hook that formats log entries with its own formatter
+// and writes them to a file (typically a RotatingFile)
+type FileLogHook struct {
+	Writer         io.Writer        // destination of the formatted entries
+	AcceptedLevels []logrus.Level   // levels that trigger this hook; nil means all levels
+	Disabled       bool             // allows the hook to be temporarily silenced
+	formatter      logrus.Formatter // formatter used to serialize log entries
+	ctl            hookctl.Control  // runtime changes of Disabled and AcceptedLevels
+}
+
+// NewFileLogHook creates a FileLogHook writing entries formatted by formatter to w.
+// Pass nil for acceptedLevels to receive all levels
+func NewFileLogHook(w io.Writer, formatter logrus.Formatter, acceptedLevels []logrus.Level) *FileLogHook {
+	return &FileLogHook{
+		Writer:         w,
+		AcceptedLevels: acceptedLevels,
+		formatter:      formatter,
+	}
+}
+
+// Levels returns the set of log levels this hook responds to.
+// Required by the logrus.Hook interface.
+func (h *FileLogHook) Levels() []logrus.Level {
+	levels := h.ctl.Levels(h.AcceptedLevels)
+	if levels == nil {
+		return logrus.AllLevels
+	}
+	return levels
+}
+
+// IsEnabled reports whether the hook fires
+func (h *FileLogHook) IsEnabled() bool {
+	return h.ctl.Enabled(!h.Disabled)
+}
+
+// SetEnabled enables or disables the hook, safely while it fires
+func (h *FileLogHook) SetEnabled(enabled bool) {
+	h.ctl.SetEnabled(enabled)
+}
+
+// SetLevels changes the levels the hook accepts, safely while it fires
+func (h *FileLogHook) SetLevels(levels []logrus.Level) {
+	h.ctl.SetLevels(levels)
+}
+
+// Fire formats the log entry and writes it to the file.
+// Required by the logrus.Hook interface.
+func (h *FileLogHook) Fire(entry *logrus.Entry) error {
+	if !h.IsEnabled() {
+		return nil
+	}
+
+	formatted, err := h.formatter.Format(entry)
+	if err != nil {
+		fmt.Printf("file_log: failed to format log entry: %v\n", err)
+		return nil // don't propagate formatter errors to logrus
+	}
+
+	if _, err = h.Writer.Write(formatted); err != nil {
+		fmt.Printf("file_log: failed to write log entry: %v\n", err)
+	}
+	return nil
+}
+
+// Flush commits written entries to stable storage if the writer supports it
+func (h *FileLogHook) Flush(_ context.Context) error {
+	if s, ok := h.Writer.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// Close closes the writer if it supports it
+func (h *FileLogHook) Close() error {
+	if c, ok := h.Writer.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,345 @@
+package file_log
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is the timestamp added to the names of rotated files.
+// It sorts lexically in time order
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// rotateRetryInterval is how long writes go on to the current file after a failed rotation before trying again
+const rotateRetryInterval = time.Minute
+
+// RotatingFileCfg configures a RotatingFile
+type RotatingFileCfg struct {
+	Path        string        // path of the active log file, e.g. /var/log/app/app.log
+	MaxSizeMB   int           // rotate when the file would exceed this size; 0 disables size rotation
+	Daily       bool          // rotate when the day changes
+	Compress    bool          // gzip rotated files
+	MaxAge      time.Duration // remove rotated files older than this; 0 keeps them
+	MaxBackups  int           // keep at most this many rotated files; 0 keeps them all
+	ReopenOnHUP bool          // reopen Path on SIGHUP, for use with an external logrotate
+}
+
+// RotatingFile is an io.WriteCloser writing to a file which it rotates by size and/or daily.
+// Rotated files are renamed with a timestamp (app-2024-05-11T19-30-09.000.log),
+// optionally gzipped, and pruned by age and count in the background
+type RotatingFile struct {
+	cfg RotatingFileCfg
+
+	mu       sync.Mutex // guards the fields below
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	retryAt  time.Time // a rotation failed, don't try again before this
+
+	housekeeping sync.WaitGroup // compression and pruning in progress
+	stopHUP      func()
+	stopHUPOnce  sync.Once
+}
+
+// NewRotatingFile opens (or creates) the file at cfg.Path for appending
+func NewRotatingFile(cfg RotatingFileCfg) (*RotatingFile, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("file_log: no path given")
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("file_log: unable to create log directory: %w", err)
+	}
+
+	rf := &RotatingFile{cfg: cfg}
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+
+	if cfg.ReopenOnHUP {
+		rf.stopHUP = notifyHUP(func() {
+			if err := rf.Reopen(); err != nil {
+				fmt.Println("file_log: unable to reopen log file on SIGHUP:", err)
+			}
+		})
+	}
+	return rf, nil
+}
+
+// CheckPath reports whether a log file can be opened for appending at path, creating its directory
+func CheckPath(path string) error {
+	if path == "" {
+		return fmt.Errorf("file_log: no path given")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("file_log: unable to create log directory: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("file_log: unable to open log file: %w", err)
+	}
+	return f.Close()
+}
+
+// Write writes p to the file, rotating it first if needed.
+// If rotating fails, p still goes to the current file and rotating is retried after rotateRetryInterval
+func (rf *RotatingFile) Write(p []byte) (n int, err error) {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.file == nil {
+		return 0, fmt.Errorf("file_log: file %s is closed", rf.cfg.Path)
+	}
+
+	if rf.needsRotation(int64(len(p))) && !time.Now().Before(rf.retryAt) {
+		if err := rf.rotate(); err != nil {
+			rf.retryAt = time.Now().Add(rotateRetryInterval)
+			fmt.Printf("file_log: unable to rotate log file, retrying in %s: %v\n", rotateRetryInterval, err)
+			if rf.file == nil { // not even the current file could be reopened
+				return 0, err
+			}
+		}
+	}
+
+	n, err = rf.file.Write(p)
+	rf.size += int64(n)
+	return
+}
+
+// Rotate rotates the file now
+func (rf *RotatingFile) Rotate() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	return rf.rotate()
+}
+
+// Reopen closes and reopens the file at the configured path.
+// Use it after an external tool has moved the file away
+func (rf *RotatingFile) Reopen() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.file != nil {
+		_ = rf.file.Close()
+	}
+	return rf.open()
+}
+
+// Sync commits the file to stable storage
+func (rf *RotatingFile) Sync() error {
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if rf.file == nil {
+		return nil
+	}
+	return rf.file.Sync()
+}
+
+// Close closes the file and waits for background compression and pruning to complete
+func (rf *RotatingFile) Close() (err error) {
+	if rf.stopHUP != nil {
+		rf.stopHUPOnce.Do(rf.stopHUP)
+	}
+
+	rf.mu.Lock()
+	if rf.file != nil {
+		err = rf.file.Close()
+		rf.file = nil
+	}
+	rf.mu.Unlock()
+
+	rf.housekeeping.Wait()
+	return
+}
+
+func (rf *RotatingFile) open() error {
+	f, err := os.OpenFile(rf.cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("file_log: unable to open log file: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return fmt.Errorf("file_log: unable to stat log file: %w", err)
+	}
+
+	rf.file = f
+	rf.size = info.Size()
+	rf.openedAt = time.Now()
+	if rf.size > 0 { // an existing file belongs to the day it was last written
+		rf.openedAt = info.ModTime()
+	}
+	return nil
+}
+
+func (rf *RotatingFile) needsRotation(writeLen int64) bool {
+	if rf.size == 0 {
+		return false // never rotate an empty file
+	}
+	if max := int64(rf.cfg.MaxSizeMB) * 1024 * 1024; max > 0 && rf.size+writeLen > max {
+		return true
+	}
+	if rf.cfg.Daily {
+		y1, m1, d1 := rf.openedAt.Date()
+		y2, m2, d2 := time.Now().Date()
+		return y1 != y2 || m1 != m2 || d1 != d2
+	}
+	return false
+}
+
+// rotate renames the current file to a backup name and opens a new one. rf.mu must be held
+func (rf *RotatingFile) rotate() error {
+	if rf.file != nil {
+		_ = rf.file.Close()
+		rf.file = nil
+	}
+
+	backup := rf.backupName(time.Now())
+	if err := os.Rename(rf.cfg.Path, backup); err != nil && !os.IsNotExist(err) {
+		// Keep logging to the current file rather than stopping for good
+		if openErr := rf.open(); openErr != nil {
+			return errors.Join(fmt.Errorf("file_log: unable to rename log file: %w", err), openErr)
+		}
+		return fmt.Errorf("file_log: unable to rename log file: %w", err)
+	}
+
+	if err := rf.open(); err != nil {
+		return err
+	}
+	rf.retryAt = time.Time{}
+
+	rf.housekeeping.Add(1)
+	go func() {
+		defer rf.housekeeping.Done()
+		rf.compressAndPrune(backup)
+	}()
+	return nil
+}
+
+// backupName returns an unused name for a file rotated at t, e.g. /logs/app-2024-05-11T19-30-09.000.log
+func (rf *RotatingFile) backupName(t time.Time) string {
+	dir, base, ext := rf.nameParts()
+	for {
+		name := filepath.Join(dir, base+"-"+t.Format(backupTimeFormat)+ext)
+		if !fileExists(name) && !fileExists(name+".gz") {
+			return name
+		}
+		t = t.Add(time.Millisecond) // rotated twice within a millisecond
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func (rf *RotatingFile) nameParts() (dir, base, ext string) {
+	dir = filepath.Dir(rf.cfg.Path)
+	ext = filepath.Ext(rf.cfg.Path)
+	base = strings.TrimSuffix(filepath.Base(rf.cfg.Path), ext)
+	return
+}
+
+var housekeepingMu sync.Mutex // serializes compression and pruning
+
+func (rf *RotatingFile) compressAndPrune(backup string) {
+	housekeepingMu.Lock()
+	defer housekeepingMu.Unlock()
+
+	if rf.cfg.Compress {
+		if err := gzipFile(backup); err != nil {
+			fmt.Println("file_log: unable to compress rotated file:", err)
+		}
+	}
+
+	if err := rf.prune(); err != nil {
+		fmt.Println("file_log: unable to remove old log files:", err)
+	}
+}
+
+// prune removes rotated files beyond MaxBackups or older than MaxAge
+func (rf *RotatingFile) prune() error {
+	if rf.cfg.MaxBackups <= 0 && rf.cfg.MaxAge <= 0 {
+		return nil
+	}
+
+	dir, base, ext := rf.nameParts()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	type backupFile struct {
+		name    string
+		rotated time.Time
+	}
+	var backups []backupFile
+
+	for _, entry := range entries {
+		name := entry.Name()
+		stamp, ok := strings.CutPrefix(name, base+"-")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		stamp, ok = strings.CutSuffix(strings.TrimSuffix(stamp, ".gz"), ext)
+		if !ok {
+			continue
+		}
+		rotated, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+		if err != nil {
+			continue // not one of ours
+		}
+		backups = append(backups, backupFile{name: name, rotated: rotated})
+	}
+
+	sort.Slice(backups, func(i, j int) bool { // newest first
+		return backups[i].rotated.After(backups[j].rotated)
+	})
+
+	for i, bf := range backups {
+		tooMany := rf.cfg.MaxBackups > 0 && i >= rf.cfg.MaxBackups
+		tooOld := rf.cfg.MaxAge > 0 && time.Since(bf.rotated) > rf.cfg.MaxAge
+		if tooMany || tooOld {
+			if err := os.Remove(filepath.Join(dir, bf.name)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// gzipFile compresses path to path.gz and removes path
+func gzipFile(path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err == nil {
+		err = gz.Close()
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(path + ".gz")
+		return err
+	}
+
+	return os.Remove(path)
+}
@@ -0,0 +1,32 @@
+//go:build !windows
+
+package file_log
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// notifyHUP calls fn on each SIGHUP until the returned stop function is called
+func notifyHUP(fn func()) (stop func()) {
+	sigs := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sigs, syscall.SIGHUP)
+
+	go func() {
+		for {
+			select {
+			case <-sigs:
+				fn()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(sigs)
+		close(done)
+	}
+}
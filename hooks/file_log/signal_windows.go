@@ -0,0 +1,8 @@
+//go:build windows
+
+package file_log
+
+// notifyHUP is a no-op as there is no SIGHUP on Windows
+func notifyHUP(fn func()) (stop func()) {
+	return func() {}
+}
@@ -0,0 +1,244 @@
+package fluent_log
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rohanthewiz/logger/internal/batch"
+	"github.com/rohanthewiz/logger/internal/hookctl"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultBatchSize  = 500
+	DefaultBatchWait  = time.Second
+	defaultMaxRetries = 5
+	defaultMinBackoff = 500 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+	defaultTimeout    = 10 * time.Second
+	closeTimeout      = 5 * time.Second
+
+	levelPlaceholder = "{level}"
+)
+
+// FluentHook is a logrus hook that sends entries to Fluentd or Fluent Bit over the forward protocol.
+// Entries are sent in batches as PackedForward messages, one per tag. A "{level}" in Tag is
+// replaced by the entry's level, e.g. "billing.{level}" gives billing.error.
+// With RequireAck each message carries a chunk id which the server must acknowledge;
+// unacknowledged messages are resent over a new connection
+type FluentHook struct {
+	Network        string         // "tcp" | "unix"
+	Address        string         // host:port, or the socket path for "unix"
+	Tag            string         // tag of entries
+	RequireAck     bool           // wait for the server to acknowledge each message
+	Timeout        time.Duration  // for connecting, writing and waiting for acks
+	BatchSize      int            // entries per message
+	BatchWait      time.Duration  // longest an entry waits for its batch to fill
+	Backoff        batch.Backoff  // retries of failed sends
+	AcceptedLevels []logrus.Level // levels that trigger this hook
+	Disabled       bool           // allows the hook to be temporarily silenced
+
+	ctl         hookctl.Control // runtime changes of Disabled and AcceptedLevels
+	conn        net.Conn        // only used by the batcher's goroutine
+	batcherOnce sync.Once
+	batcher     *batch.Batcher[event] // created with the first entry, from BatchSize and BatchWait
+}
+
+// event is an entry encoded as the msgpack array [time, record]
+type event struct {
+	tag  string
+	data []byte
+}
+
+// NewFluentHook creates a FluentHook. Pass an empty tag to use the program name.
+// Call Close to send remaining entries when done
+func NewFluentHook(network, address, tag string, acceptedLevels []logrus.Level) *FluentHook {
+	if tag == "" {
+		tag = filepath.Base(os.Args[0])
+	}
+	return &FluentHook{
+		Network:        network,
+		Address:        address,
+		Tag:            tag,
+		Timeout:        defaultTimeout,
+		BatchSize:      DefaultBatchSize,
+		BatchWait:      DefaultBatchWait,
+		Backoff:        batch.Backoff{MaxRetries: defaultMaxRetries, Min: defaultMinBackoff, Max: defaultMaxBackoff},
+		AcceptedLevels: acceptedLevels,
+	}
+}
+
+// Levels returns the set of log levels this hook responds to.
+// Required by the logrus.Hook interface.
+func (h *FluentHook) Levels() []logrus.Level {
+	return h.ctl.Levels(h.AcceptedLevels)
+}
+
+// IsEnabled reports whether the hook fires
+func (h *FluentHook) IsEnabled() bool {
+	return h.ctl.Enabled(!h.Disabled)
+}
+
+// SetEnabled enables or disables the hook, safely while it fires
+func (h *FluentHook) SetEnabled(enabled bool) {
+	h.ctl.SetEnabled(enabled)
+}
+
+// SetLevels changes the levels the hook accepts, safely while it fires
+func (h *FluentHook) SetLevels(levels []logrus.Level) {
+	h.ctl.SetLevels(levels)
+}
+
+// Fire queues the log entry for the next message.
+// Required by the logrus.Hook interface.
+func (h *FluentHook) Fire(entry *logrus.Entry) error {
+	if !h.IsEnabled() {
+		return nil
+	}
+
+	fields := make(map[string]any, len(entry.Data))
+	for k, v := range entry.Data {
+		if k == "message" || k == "level" {
+			k = "fields." + k // keep fields clashing with the entry's own
+		}
+		fields[k] = v
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	data := appendArrayHeader(nil, 2)
+	data = appendEventTime(data, entry.Time)
+	data = appendMapHeader(data, len(keys)+2)
+	data = appendString(appendString(data, "message"), entry.Message)
+	data = appendString(appendString(data, "level"), entry.Level.String())
+	for _, k := range keys {
+		data = appendValue(appendString(data, k), fields[k])
+	}
+
+	tag := strings.ReplaceAll(h.Tag, levelPlaceholder, entry.Level.String())
+	if !h.batch().Add(event{tag: tag, data: data}) {
+		fmt.Println("fluent_log: queue full, dropping log entry")
+	}
+	return nil
+}
+
+// Flush sends queued entries, waiting for the send to complete or ctx to be done
+func (h *FluentHook) Flush(ctx context.Context) error {
+	return h.batch().Flush(ctx)
+}
+
+// Close sends queued entries, stops the sender and closes the connection
+func (h *FluentHook) Close() error {
+	if err := h.batch().Close(closeTimeout); err != nil {
+		// The sender may still be writing, so the connection is closed once it stops
+		go func() {
+			<-h.batch().Done()
+			h.closeConn()
+		}()
+		return err
+	}
+	h.closeConn() // the sender has stopped, so the connection is ours
+	return nil
+}
+
+func (h *FluentHook) batch() *batch.Batcher[event] {
+	h.batcherOnce.Do(func() { h.batcher = batch.New(h.BatchSize, h.BatchWait, h.forward) })
+	return h.batcher
+}
+
+// forward sends the events as a PackedForward message per tag
+func (h *FluentHook) forward(events []event) {
+	var tags []string
+	byTag := map[string][]byte{}
+	counts := map[string]int{}
+	for _, ev := range events {
+		if _, ok := byTag[ev.tag]; !ok {
+			tags = append(tags, ev.tag)
+		}
+		byTag[ev.tag] = append(byTag[ev.tag], ev.data...)
+		counts[ev.tag]++
+	}
+
+	for _, tag := range tags {
+		msg, chunk := h.packedForward(tag, byTag[tag], counts[tag])
+
+		err := h.Backoff.Retry(h.batch(), func() (time.Duration, error) {
+			err := h.send(msg, chunk)
+			if err != nil {
+				h.closeConn() // reconnect for the next attempt
+			}
+			return 0, err
+		})
+		if err != nil {
+			fmt.Printf("fluent_log: dropping %d entries tagged %s: %v\n", counts[tag], tag, err)
+		}
+	}
+}
+
+// packedForward encodes [tag, entries, option]. entries is the concatenated
+// [time, record] arrays, option holds the entry count and the chunk id if acks are required
+func (h *FluentHook) packedForward(tag string, entries []byte, count int) (msg []byte, chunk string) {
+	option := map[string]any{"size": count}
+	if h.RequireAck {
+		id := make([]byte, 16)
+		_, _ = rand.Read(id)
+		chunk = base64.StdEncoding.EncodeToString(id)
+		option["chunk"] = chunk
+	}
+
+	msg = appendArrayHeader(nil, 3)
+	msg = appendString(msg, tag)
+	msg = appendBin(msg, entries)
+	msg = appendValue(msg, option)
+	return
+}
+
+// send writes msg and, if chunk is set, waits for its ack
+func (h *FluentHook) send(msg []byte, chunk string) error {
+	if h.conn == nil {
+		conn, err := net.DialTimeout(h.Network, h.Address, h.Timeout)
+		if err != nil {
+			return err
+		}
+		h.conn = conn
+	}
+
+	_ = h.conn.SetWriteDeadline(time.Now().Add(h.Timeout))
+	if _, err := h.conn.Write(msg); err != nil {
+		return err
+	}
+	if chunk == "" {
+		return nil
+	}
+
+	_ = h.conn.SetReadDeadline(time.Now().Add(h.Timeout))
+	ack, err := readAck(h.conn)
+	if err != nil {
+		return fmt.Errorf("no ack: %w", err)
+	}
+	if ack != chunk {
+		return errors.New("ack for an unexpected chunk")
+	}
+	return nil
+}
+
+func (h *FluentHook) closeConn() {
+	if h.conn != nil {
+		_ = h.conn.Close()
+		h.conn = nil
+	}
+}
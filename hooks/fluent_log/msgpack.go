@@ -0,0 +1,237 @@
+package fluent_log
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// The subset of MessagePack (https://github.com/msgpack/msgpack/blob/master/spec.md)
+// the forward protocol needs
+
+func appendNil(b []byte) []byte {
+	return append(b, 0xc0)
+}
+
+func appendBool(b []byte, v bool) []byte {
+	if v {
+		return append(b, 0xc3)
+	}
+	return append(b, 0xc2)
+}
+
+func appendInt(b []byte, v int64) []byte {
+	switch {
+	case v >= 0:
+		return appendUint(b, uint64(v))
+	case v >= -32:
+		return append(b, byte(v))
+	case v >= math.MinInt8:
+		return append(b, 0xd0, byte(v))
+	case v >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(v))
+	case v >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(v))
+	}
+}
+
+func appendUint(b []byte, v uint64) []byte {
+	switch {
+	case v <= 0x7f:
+		return append(b, byte(v))
+	case v <= math.MaxUint8:
+		return append(b, 0xcc, byte(v))
+	case v <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(v))
+	case v <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(v))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xcf), v)
+	}
+}
+
+func appendFloat(b []byte, v float64) []byte {
+	return binary.BigEndian.AppendUint64(append(b, 0xcb), math.Float64bits(v))
+}
+
+func appendString(b []byte, s string) []byte {
+	switch n := len(s); {
+	case n <= 31:
+		b = append(b, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		b = append(b, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xda), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xdb), uint32(n))
+	}
+	return append(b, s...)
+}
+
+func appendBin(b []byte, v []byte) []byte {
+	switch n := len(v); {
+	case n <= math.MaxUint8:
+		b = append(b, 0xc4, byte(n))
+	case n <= math.MaxUint16:
+		b = binary.BigEndian.AppendUint16(append(b, 0xc5), uint16(n))
+	default:
+		b = binary.BigEndian.AppendUint32(append(b, 0xc6), uint32(n))
+	}
+	return append(b, v...)
+}
+
+func appendArrayHeader(b []byte, n int) []byte {
+	switch {
+	case n <= 15:
+		return append(b, 0x90|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xdc), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, 0xdd), uint32(n))
+	}
+}
+
+func appendMapHeader(b []byte, n int) []byte {
+	switch {
+	case n <= 15:
+		return append(b, 0x80|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xde), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, 0xdf), uint32(n))
+	}
+}
+
+// appendEventTime appends t as the forward protocol's EventTime: ext type 0 holding
+// big endian seconds and nanoseconds
+func appendEventTime(b []byte, t time.Time) []byte {
+	b = append(b, 0xd7, 0x00) // fixext 8, type 0
+	b = binary.BigEndian.AppendUint32(b, uint32(t.Unix()))
+	return binary.BigEndian.AppendUint32(b, uint32(t.Nanosecond()))
+}
+
+// appendValue appends v, falling back to its string form for types msgpack has no equivalent of
+func appendValue(b []byte, v any) []byte {
+	switch val := v.(type) {
+	case nil:
+		return appendNil(b)
+	case string:
+		return appendString(b, val)
+	case bool:
+		return appendBool(b, val)
+	case []byte:
+		return appendBin(b, val)
+	case error:
+		return appendString(b, val.Error())
+	case fmt.Stringer:
+		return appendString(b, val.String())
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return appendInt(b, rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return appendUint(b, rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		return appendFloat(b, rv.Float())
+	case reflect.Slice, reflect.Array:
+		b = appendArrayHeader(b, rv.Len())
+		for i := range rv.Len() {
+			b = appendValue(b, rv.Index(i).Interface())
+		}
+		return b
+	case reflect.Map:
+		if rv.Type().Key().Kind() == reflect.String {
+			keys := rv.MapKeys()
+			sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+			b = appendMapHeader(b, len(keys))
+			for _, k := range keys {
+				b = appendString(b, k.String())
+				b = appendValue(b, rv.MapIndex(k).Interface())
+			}
+			return b
+		}
+	}
+	return appendString(b, fmt.Sprintf("%v", v))
+}
+
+// readAck reads the server's response to a chunk, a map like {"ack": "<chunk id>"},
+// returning the ack value
+func readAck(r io.Reader) (string, error) {
+	n, err := readMapHeader(r)
+	if err != nil {
+		return "", err
+	}
+
+	var ack string
+	for range n {
+		key, err := readString(r)
+		if err != nil {
+			return "", err
+		}
+		val, err := readString(r)
+		if err != nil {
+			return "", err
+		}
+		if key == "ack" {
+			ack = val
+		}
+	}
+	return ack, nil
+}
+
+func readMapHeader(r io.Reader) (int, error) {
+	var b [1]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return 0, err
+	}
+
+	switch {
+	case b[0]&0xf0 == 0x80:
+		return int(b[0] & 0x0f), nil
+	case b[0] == 0xde:
+		var n [2]byte
+		_, err := io.ReadFull(r, n[:])
+		return int(binary.BigEndian.Uint16(n[:])), err
+	}
+	return 0, fmt.Errorf("expected a map in the ack, got 0x%x", b[0])
+}
+
+func readString(r io.Reader) (string, error) {
+	var b [1]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return "", err
+	}
+
+	var n int
+	switch {
+	case b[0]&0xe0 == 0xa0:
+		n = int(b[0] & 0x1f)
+	case b[0] == 0xd9:
+		var l [1]byte
+		if _, err := io.ReadFull(r, l[:]); err != nil {
+			return "", err
+		}
+		n = int(l[0])
+	case b[0] == 0xda:
+		var l [2]byte
+		if _, err := io.ReadFull(r, l[:]); err != nil {
+			return "", err
+		}
+		n = int(binary.BigEndian.Uint16(l[:]))
+	default:
+		return "", errors.New("expected a string in the ack")
+	}
+
+	s := make([]byte, n)
+	_, err := io.ReadFull(r, s)
+	return string(s), err
+}
@@ -0,0 +1,383 @@
+package gelf_log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rohanthewiz/logger/internal/batch"
+	"github.com/rohanthewiz/logger/internal/hookctl"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultChunkSize     = 1420 // fits a WAN MTU; use 8154 on a LAN
+	MinChunkSize         = 128  // leaves room for data after the chunk header
+	defaultRetryInterval = time.Second
+	writeTimeout         = 5 * time.Second
+	closeTimeout         = 5 * time.Second
+	batchSize            = 100
+	batchWait            = 100 * time.Millisecond
+	maxChunks            = 128
+	chunkHeaderLen       = 12
+)
+
+// Compression methods for UDP. TCP frames are never compressed
+const (
+	CompressGzip = "gzip"
+	CompressZlib = "zlib"
+	CompressNone = "none"
+)
+
+// levels maps logrus levels onto the syslog severities GELF uses
+var levels = map[logrus.Level]int{
+	logrus.PanicLevel: 1, // alert
+	logrus.FatalLevel: 2, // critical
+	logrus.ErrorLevel: 3, // error
+	logrus.WarnLevel:  4, // warning
+	logrus.InfoLevel:  6, // informational
+	logrus.DebugLevel: 7, // debug
+	logrus.TraceLevel: 7, // debug
+}
+
+var invalidFieldChars = regexp.MustCompile(`[^\w.\-]`)
+
+// GELFHook is a logrus hook that sends entries to Graylog as GELF 1.1 messages.
+// Over UDP messages are compressed and split into chunks when larger than ChunkSize;
+// over TCP they are sent uncompressed, each terminated by a null byte.
+// Fields become additional fields ("_"-prefixed); the location of logged errors becomes _file and _line.
+// Entries are sent in the background; call Close to send remaining entries when done
+type GELFHook struct {
+	Network        string         // "udp" | "tcp"
+	Address        string         // host:port of the GELF input
+	Host           string         // the "host" of messages
+	Compression    string         // UDP only: CompressGzip | CompressZlib | CompressNone
+	ChunkSize      int            // UDP only: maximum datagram size, at least MinChunkSize
+	RetryInterval  time.Duration  // minimum wait between connection attempts
+	AcceptedLevels []logrus.Level // levels that trigger this hook
+	Disabled       bool           // allows the hook to be temporarily silenced
+
+	ctl         hookctl.Control // runtime changes of Disabled and AcceptedLevels
+	conn        net.Conn        // only used by the batcher's goroutine, like the fields below
+	lastAttempt time.Time       // of a failed connection attempt
+	batcherOnce sync.Once
+	batcher     *batch.Batcher[[]byte]
+}
+
+// NewGELFHook creates a GELFHook with gzip compression and the default chunk size
+func NewGELFHook(network, address string, acceptedLevels []logrus.Level) *GELFHook {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return &GELFHook{
+		Network:        network,
+		Address:        address,
+		Host:           host,
+		Compression:    CompressGzip,
+		ChunkSize:      DefaultChunkSize,
+		RetryInterval:  defaultRetryInterval,
+		AcceptedLevels: acceptedLevels,
+	}
+}
+
+// Levels returns the set of log levels this hook responds to.
+// Required by the logrus.Hook interface.
+func (h *GELFHook) Levels() []logrus.Level {
+	return h.ctl.Levels(h.AcceptedLevels)
+}
+
+// IsEnabled reports whether the hook fires
+func (h *GELFHook) IsEnabled() bool {
+	return h.ctl.Enabled(!h.Disabled)
+}
+
+// SetEnabled enables or disables the hook, safely while it fires
+func (h *GELFHook) SetEnabled(enabled bool) {
+	h.ctl.SetEnabled(enabled)
+}
+
+// SetLevels changes the levels the hook accepts, safely while it fires
+func (h *GELFHook) SetLevels(levels []logrus.Level) {
+	h.ctl.SetLevels(levels)
+}
+
+// Fire queues the log entry to be sent to Graylog.
+// Required by the logrus.Hook interface.
+func (h *GELFHook) Fire(entry *logrus.Entry) error {
+	if !h.IsEnabled() {
+		return nil
+	}
+
+	msg, err := json.Marshal(h.Message(entry))
+	if err != nil {
+		fmt.Println("gelf_log: unable to encode message:", err)
+		return nil
+	}
+
+	if !h.batch().Add(msg) {
+		fmt.Println("gelf_log: queue full, dropping log entry")
+	}
+	return nil
+}
+
+// Flush sends queued entries, waiting for the send to complete or ctx to be done
+func (h *GELFHook) Flush(ctx context.Context) error {
+	return h.batch().Flush(ctx)
+}
+
+func (h *GELFHook) batch() *batch.Batcher[[]byte] {
+	h.batcherOnce.Do(func() { h.batcher = batch.New(batchSize, batchWait, h.send) })
+	return h.batcher
+}
+
+// send writes the messages, dropping the rest of them once one can't be sent or Close has timed out.
+// A message too large to be chunked is dropped on its own
+func (h *GELFHook) send(msgs [][]byte) {
+	for i, msg := range msgs {
+		select {
+		case <-h.batch().Abandoned():
+			fmt.Printf("gelf_log: dropping %d entries: %v\n", len(msgs)-i, batch.ErrAbandoned)
+			return
+		default:
+		}
+		if err := h.sendMsg(msg); errors.Is(err, errTooLarge) {
+			fmt.Println("gelf_log: unable to send to Graylog:", err)
+		} else if err != nil {
+			fmt.Printf("gelf_log: dropping %d entries: %v\n", len(msgs)-i, err)
+			return
+		}
+	}
+}
+
+func (h *GELFHook) sendMsg(msg []byte) (err error) {
+	// A write to a broken TCP connection fails, so reconnect and try once more
+	for attempt := 0; attempt < 2; attempt++ {
+		if err = h.connect(); err != nil {
+			return fmt.Errorf("unable to connect to Graylog: %w", err)
+		}
+		if err = h.write(msg); err == nil || errors.Is(err, errTooLarge) {
+			return err
+		}
+		h.closeConn()
+	}
+	return fmt.Errorf("unable to send to Graylog: %w", err)
+}
+
+// Message builds the GELF message for the entry
+func (h *GELFHook) Message(entry *logrus.Entry) map[string]any {
+	level, ok := levels[entry.Level]
+	if !ok {
+		level = levels[logrus.InfoLevel]
+	}
+
+	msg := map[string]any{
+		"version":   "1.1",
+		"host":      h.Host,
+		"timestamp": float64(entry.Time.UnixMicro()) / 1e6,
+		"level":     level,
+	}
+
+	short, _, multiline := strings.Cut(entry.Message, "\n")
+	msg["short_message"] = short
+	if multiline {
+		msg["full_message"] = entry.Message
+	}
+
+	for k, v := range entry.Data {
+		msg[fieldName(k)] = fieldValue(v)
+	}
+
+	if file, line := location(entry); file != "" {
+		msg["_file"], msg["_line"] = file, line
+	}
+	return msg
+}
+
+// Close sends queued entries, stops the sender and closes the connection to Graylog
+func (h *GELFHook) Close() error {
+	if err := h.batch().Close(closeTimeout); err != nil {
+		// The sender may still be writing, so the connection is closed once it stops
+		go func() {
+			<-h.batch().Done()
+			h.closeConn()
+		}()
+		return err
+	}
+	h.closeConn() // the sender has stopped, so the connection is ours
+	return nil
+}
+
+var errTooLarge = errors.New("message needs more than 128 chunks")
+
+// write sends msg over the connection
+func (h *GELFHook) write(msg []byte) error {
+	_ = h.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+
+	if h.Network == "tcp" {
+		_, err := h.conn.Write(append(msg, 0))
+		return err
+	}
+
+	msg, err := compress(msg, h.Compression)
+	if err != nil {
+		return err
+	}
+	chunkSize := max(h.ChunkSize, MinChunkSize)
+	if len(msg) <= chunkSize {
+		_, err = h.conn.Write(msg)
+		return err
+	}
+
+	chunks, err := chunk(msg, chunkSize)
+	if err != nil {
+		return err
+	}
+	for _, c := range chunks {
+		if _, err = h.conn.Write(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// connect dials Graylog unless already connected, at most once per RetryInterval
+func (h *GELFHook) connect() error {
+	if h.conn != nil {
+		return nil
+	}
+	if time.Since(h.lastAttempt) < h.RetryInterval {
+		return errors.New("waiting to reconnect")
+	}
+
+	conn, err := net.DialTimeout(h.Network, h.Address, writeTimeout)
+	if err != nil {
+		h.lastAttempt = time.Now()
+		return err
+	}
+	h.conn, h.lastAttempt = conn, time.Time{}
+	return nil
+}
+
+func (h *GELFHook) closeConn() {
+	if h.conn != nil {
+		_ = h.conn.Close()
+		h.conn = nil
+	}
+}
+
+func compress(msg []byte, method string) ([]byte, error) {
+	var buf bytes.Buffer
+	var w interface {
+		Write([]byte) (int, error)
+		Close() error
+	}
+
+	switch method {
+	case CompressGzip:
+		w = gzip.NewWriter(&buf)
+	case CompressZlib:
+		w = zlib.NewWriter(&buf)
+	default:
+		return msg, nil
+	}
+
+	if _, err := w.Write(msg); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// chunk splits msg into GELF chunks: magic bytes 0x1e 0x0f, an 8 byte message id,
+// the sequence number and count, then the data. chunkSize must exceed chunkHeaderLen
+func chunk(msg []byte, chunkSize int) ([][]byte, error) {
+	dataSize := chunkSize - chunkHeaderLen
+	count := (len(msg) + dataSize - 1) / dataSize
+	if count > maxChunks {
+		return nil, errTooLarge
+	}
+
+	id := make([]byte, 8)
+	_, _ = rand.Read(id)
+
+	chunks := make([][]byte, 0, count)
+	for i := range count {
+		data := msg[i*dataSize : min((i+1)*dataSize, len(msg))]
+		c := make([]byte, 0, chunkHeaderLen+len(data))
+		c = append(c, 0x1e, 0x0f)
+		c = append(c, id...)
+		c = append(c, byte(i), byte(count))
+		chunks = append(chunks, append(c, data...))
+	}
+	return chunks, nil
+}
+
+// fieldName returns key as an additional field name: "_" prefixed, with characters
+// GELF doesn't allow replaced. "_id" is reserved, so "id" becomes "__id"
+func fieldName(key string) string {
+	name := "_" + invalidFieldChars.ReplaceAllString(key, "_")
+	if name == "_id" {
+		name = "__id"
+	}
+	return name
+}
+
+// fieldValue keeps numbers as numbers; GELF only allows strings and numbers.
+// NaN and ±Inf become strings, as JSON can't represent them
+func fieldValue(v any) any {
+	switch f := v.(type) {
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return v
+	case float32:
+		if math.IsNaN(float64(f)) || math.IsInf(float64(f), 0) {
+			return fmt.Sprintf("%v", v)
+		}
+		return v
+	case float64:
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Sprintf("%v", v)
+		}
+		return v
+	case string:
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// location returns the file and line of the entry from the logrus caller if reported,
+// otherwise from the "location" field (file:line) of logged errors
+func location(entry *logrus.Entry) (file string, line int) {
+	if entry.Caller != nil {
+		return entry.Caller.File, entry.Caller.Line
+	}
+
+	// Wrapped errors carry locations like "origin.go:10 -> caller.go:20", the first is where it started
+	loc, ok := entry.Data["location"].(string)
+	if !ok {
+		return
+	}
+	loc, _, _ = strings.Cut(loc, " -> ")
+	if i := strings.LastIndex(loc, ":"); i > 0 {
+		if n, err := strconv.Atoi(loc[i+1:]); err == nil {
+			return loc[:i], n
+		}
+	}
+	return
+}
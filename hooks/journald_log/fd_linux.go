@@ -0,0 +1,66 @@
+//go:build linux
+
+package journald_log
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+func isTooLarge(err error) bool {
+	return errors.Is(err, syscall.EMSGSIZE) || errors.Is(err, syscall.ENOBUFS)
+}
+
+// sendViaFD writes data to a sealed memfd (or an unlinked temporary file) and passes
+// its file descriptor to journald, which reads the entry from it
+func sendViaFD(conn *net.UnixConn, addr *net.UnixAddr, data []byte) error {
+	f, err := memfd(data)
+	if err != nil {
+		if f, err = tempFile(data); err != nil {
+			return fmt.Errorf("unable to pass large entry: %w", err)
+		}
+	}
+	defer func() { _ = f.Close() }()
+
+	_, _, err = conn.WriteMsgUnix(nil, syscall.UnixRights(int(f.Fd())), addr)
+	return err
+}
+
+func memfd(data []byte) (*os.File, error) {
+	fd, err := unix.MemfdCreate("journald_log", unix.MFD_ALLOW_SEALING|unix.MFD_CLOEXEC)
+	if err != nil {
+		return nil, err
+	}
+	f := os.NewFile(uintptr(fd), "journald_log")
+
+	if _, err = f.Write(data); err == nil {
+		// journald only accepts memfds which can no longer change
+		_, err = unix.FcntlInt(f.Fd(), unix.F_ADD_SEALS,
+			unix.F_SEAL_SHRINK|unix.F_SEAL_GROW|unix.F_SEAL_WRITE|unix.F_SEAL_SEAL)
+	}
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// tempFile is the fallback for kernels without memfd
+func tempFile(data []byte) (*os.File, error) {
+	f, err := os.CreateTemp("/dev/shm", "journald_log-")
+	if err != nil {
+		return nil, err
+	}
+	_ = os.Remove(f.Name()) // journald reads it via the descriptor
+
+	if _, err = f.Write(data); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return f, nil
+}
@@ -0,0 +1,17 @@
+//go:build !linux
+
+package journald_log
+
+import (
+	"errors"
+	"net"
+)
+
+func isTooLarge(err error) bool {
+	return false
+}
+
+// sendViaFD is only supported on Linux, where journald runs
+func sendViaFD(_ *net.UnixConn, _ *net.UnixAddr, _ []byte) error {
+	return errors.New("passing large entries is not supported on this platform")
+}
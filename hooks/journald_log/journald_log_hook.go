@@ -0,0 +1,236 @@
+package journald_log
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rohanthewiz/logger/internal/hookctl"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultSocketPath is where journald listens for native protocol datagrams
+const DefaultSocketPath = "/run/systemd/journal/socket"
+
+const maxFieldNameLen = 64
+
+// priorities maps logrus levels onto syslog priorities, as journald uses them
+var priorities = map[logrus.Level]int{
+	logrus.PanicLevel: 1, // alert
+	logrus.FatalLevel: 2, // critical
+	logrus.ErrorLevel: 3, // error
+	logrus.WarnLevel:  4, // warning
+	logrus.InfoLevel:  6, // informational
+	logrus.DebugLevel: 7, // debug
+	logrus.TraceLevel: 7, // debug
+}
+
+// reservedFields are the journal fields written by the hook itself
+var reservedFields = map[string]bool{
+	"MESSAGE": true, "PRIORITY": true, "SYSLOG_IDENTIFIER": true,
+	"CODE_FILE": true, "CODE_LINE": true, "CODE_FUNC": true,
+}
+
+// JournaldHook is a logrus hook that writes entries to systemd-journald over its native protocol,
+// each field becoming a journal field: MESSAGE, PRIORITY, SYSLOG_IDENTIFIER, CODE_FILE, CODE_LINE,
+// CODE_FUNC plus the entry's fields with upper-cased keys (prefixed with FIELDS_ if they clash with those).
+// Entries too large for a datagram are passed to journald in a sealed memfd
+type JournaldHook struct {
+	SocketPath       string         // journald's socket, see DefaultSocketPath
+	SyslogIdentifier string         // SYSLOG_IDENTIFIER of entries
+	AcceptedLevels   []logrus.Level // levels that trigger this hook
+	Disabled         bool           // allows the hook to be temporarily silenced
+
+	ctl  hookctl.Control // runtime changes of Disabled and AcceptedLevels
+	mu   sync.Mutex      // guards conn
+	conn *net.UnixConn
+}
+
+// NewJournaldHook creates a JournaldHook. Pass an empty identifier to use the program name
+func NewJournaldHook(socketPath, identifier string, acceptedLevels []logrus.Level) *JournaldHook {
+	if socketPath == "" {
+		socketPath = DefaultSocketPath
+	}
+	if identifier == "" {
+		identifier = filepath.Base(os.Args[0])
+	}
+	return &JournaldHook{
+		SocketPath:       socketPath,
+		SyslogIdentifier: identifier,
+		AcceptedLevels:   acceptedLevels,
+	}
+}
+
+// Levels returns the set of log levels this hook responds to.
+// Required by the logrus.Hook interface.
+func (h *JournaldHook) Levels() []logrus.Level {
+	return h.ctl.Levels(h.AcceptedLevels)
+}
+
+// IsEnabled reports whether the hook fires
+func (h *JournaldHook) IsEnabled() bool {
+	return h.ctl.Enabled(!h.Disabled)
+}
+
+// SetEnabled enables or disables the hook, safely while it fires
+func (h *JournaldHook) SetEnabled(enabled bool) {
+	h.ctl.SetEnabled(enabled)
+}
+
+// SetLevels changes the levels the hook accepts, safely while it fires
+func (h *JournaldHook) SetLevels(levels []logrus.Level) {
+	h.ctl.SetLevels(levels)
+}
+
+// Fire writes the log entry to the journal.
+// Required by the logrus.Hook interface.
+func (h *JournaldHook) Fire(entry *logrus.Entry) error {
+	if !h.IsEnabled() {
+		return nil
+	}
+
+	if err := h.send(h.Encode(entry)); err != nil {
+		fmt.Println("journald_log: unable to write to the journal:", err)
+	}
+	return nil // don't propagate delivery errors to logrus
+}
+
+// Encode serializes the entry in journald's native format
+func (h *JournaldHook) Encode(entry *logrus.Entry) []byte {
+	var buf bytes.Buffer
+
+	priority, ok := priorities[entry.Level]
+	if !ok {
+		priority = priorities[logrus.InfoLevel]
+	}
+
+	writeField(&buf, "MESSAGE", entry.Message)
+	writeField(&buf, "PRIORITY", strconv.Itoa(priority))
+	writeField(&buf, "SYSLOG_IDENTIFIER", h.SyslogIdentifier)
+
+	file, line, function := codeLocation(entry)
+	if file != "" {
+		writeField(&buf, "CODE_FILE", file)
+		writeField(&buf, "CODE_LINE", line)
+	}
+	if function != "" {
+		writeField(&buf, "CODE_FUNC", function)
+	}
+
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		name := FieldName(k)
+		if name == "" {
+			continue
+		}
+		if reservedFields[name] {
+			name = "FIELDS_" + name // a second MESSAGE etc. would be ambiguous
+		}
+		writeField(&buf, name, fmt.Sprintf("%v", entry.Data[k]))
+	}
+	return buf.Bytes()
+}
+
+// Close closes the socket
+func (h *JournaldHook) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.conn == nil {
+		return nil
+	}
+	err := h.conn.Close()
+	h.conn = nil
+	return err
+}
+
+func (h *JournaldHook) send(data []byte) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.conn == nil {
+		// An unbound datagram socket, so a restarted journald is picked up on the next write
+		conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Net: "unixgram"})
+		if err != nil {
+			return err
+		}
+		h.conn = conn
+	}
+
+	addr := &net.UnixAddr{Name: h.SocketPath, Net: "unixgram"}
+	_, _, err := h.conn.WriteMsgUnix(data, nil, addr)
+	if err != nil && isTooLarge(err) {
+		return sendViaFD(h.conn, addr, data)
+	}
+	return err
+}
+
+// writeField writes a field as NAME=value\n, or in the binary-safe form
+// NAME\n<little endian uint64 length>value\n when the value contains newlines
+func writeField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	if !strings.Contains(value, "\n") {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+
+	buf.WriteByte('\n')
+	_ = binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+// FieldName converts key into a journal field name: upper-case letters, digits and underscores,
+// not starting with an underscore (reserved for trusted fields) or a digit
+func FieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+
+	name = strings.TrimLeft(name, "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "F_" + name
+	}
+	return name[:min(len(name), maxFieldNameLen)]
+}
+
+// codeLocation returns the code location of the entry from the logrus caller if reported,
+// otherwise from the "location" (file:line) and "function" fields of logged errors
+func codeLocation(entry *logrus.Entry) (file, line, function string) {
+	if entry.Caller != nil {
+		return entry.Caller.File, strconv.Itoa(entry.Caller.Line), entry.Caller.Function
+	}
+
+	// Wrapped errors carry locations like "origin.go:10 -> caller.go:20", the first is where it started
+	if loc, ok := entry.Data["location"].(string); ok {
+		loc, _, _ = strings.Cut(loc, " -> ")
+		if i := strings.LastIndex(loc, ":"); i > 0 {
+			file, line = loc[:i], loc[i+1:]
+		}
+	}
+	if fn, ok := entry.Data["function"].(string); ok {
+		function, _, _ = strings.Cut(fn, " -> ")
+	}
+	return
+}
@@ -3,6 +3,7 @@ package log_chan
 import (
 	"fmt"
 
+	"github.com/rohanthewiz/logger/internal/hookctl"
 	"github.com/sirupsen/logrus"
 )
 
@@ -16,6 +17,7 @@ type LogChanHook struct {
 	AcceptedLevels []logrus.Level  // levels that trigger this hook; nil means all levels
 	Disabled       bool            // allows the hook to be temporarily silenced
 	formatter      logrus.Formatter // text formatter used to serialize log entries
+	ctl            hookctl.Control  // runtime changes of Disabled and AcceptedLevels
 }
 
 // allLevels enumerates every logrus severity so we have a default
@@ -43,10 +45,26 @@ func NewLogChanHook(ch chan string, acceptedLevels []logrus.Level) *LogChanHook
 // Levels returns the set of log levels this hook responds to.
 // Required by the logrus.Hook interface.
 func (h *LogChanHook) Levels() []logrus.Level {
-	if h.AcceptedLevels == nil {
+	levels := h.ctl.Levels(h.AcceptedLevels)
+	if levels == nil {
 		return allLevels
 	}
-	return h.AcceptedLevels
+	return levels
+}
+
+// IsEnabled reports whether the hook fires
+func (h *LogChanHook) IsEnabled() bool {
+	return h.ctl.Enabled(!h.Disabled)
+}
+
+// SetEnabled enables or disables the hook, safely while it fires
+func (h *LogChanHook) SetEnabled(enabled bool) {
+	h.ctl.SetEnabled(enabled)
+}
+
+// SetLevels changes the levels the hook accepts, safely while it fires
+func (h *LogChanHook) SetLevels(levels []logrus.Level) {
+	h.ctl.SetLevels(levels)
 }
 
 // AllowedLevels returns every logrus level at or above the given level.
@@ -67,7 +85,7 @@ func AllowedLevels(lvl logrus.Level) []logrus.Level {
 // if the channel cannot accept them immediately.
 // Required by the logrus.Hook interface.
 func (h *LogChanHook) Fire(entry *logrus.Entry) error {
-	if h.Disabled {
+	if !h.IsEnabled() {
 		return nil
 	}
 
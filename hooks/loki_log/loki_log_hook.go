@@ -0,0 +1,306 @@
+package loki_log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rohanthewiz/logger/internal/batch"
+	"github.com/rohanthewiz/logger/internal/hookctl"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	PushPath = "/loki/api/v1/push"
+
+	DefaultBatchSize  = 500
+	DefaultBatchWait  = time.Second
+	defaultMaxRetries = 5
+	defaultMinBackoff = 500 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+	closeTimeout      = 5 * time.Second
+)
+
+var invalidLabelChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// fallbackLabel is given to entries which would have no labels, as Loki rejects a push with an empty stream
+const fallbackLabel = "service_name"
+
+// LokiHook is a logrus hook that pushes entries to Grafana Loki.
+// Entries are queued and sent in batches, grouped into streams by their labels:
+// the static Labels plus the values of LabelFields ("level" is the entry's level).
+// An entry which would have no labels gets service_name with the program name.
+// Failed pushes are retried with exponential backoff
+type LokiHook struct {
+	URL            string            // push endpoint, e.g. http://loki:3100/loki/api/v1/push
+	Labels         map[string]string // labels of all streams, e.g. {"service": "billing"}
+	LabelFields    []string          // fields whose values become stream labels
+	TenantID       string            // sent as X-Scope-OrgID when set
+	Username       string            // basic auth, when set
+	Password       string
+	BatchSize      int           // entries per push
+	BatchWait      time.Duration // longest an entry waits for its batch to fill
+	MaxRetries     int
+	MinBackoff     time.Duration
+	MaxBackoff     time.Duration
+	Client         *http.Client
+	AcceptedLevels []logrus.Level // levels that trigger this hook
+	Disabled       bool           // allows the hook to be temporarily silenced
+
+	ctl         hookctl.Control // runtime changes of Disabled and AcceptedLevels
+	formatter   logrus.Formatter
+	batcherOnce sync.Once
+	batcher     *batch.Batcher[lokiEntry] // created with the first entry, from BatchSize and BatchWait
+}
+
+type lokiEntry struct {
+	labels string // canonical form of the stream labels, to group by
+	stream map[string]string
+	time   time.Time
+	line   string
+}
+
+// NewLokiHook creates a LokiHook. baseURL may be the Loki address (http://loki:3100) or the full push URL.
+// Call Close to send remaining entries when done
+func NewLokiHook(baseURL string, labels map[string]string, labelFields []string, acceptedLevels []logrus.Level) *LokiHook {
+	return &LokiHook{
+		URL:            pushURL(baseURL),
+		Labels:         labels,
+		LabelFields:    labelFields,
+		BatchSize:      DefaultBatchSize,
+		BatchWait:      DefaultBatchWait,
+		MaxRetries:     defaultMaxRetries,
+		MinBackoff:     defaultMinBackoff,
+		MaxBackoff:     defaultMaxBackoff,
+		Client:         &http.Client{Timeout: 10 * time.Second},
+		AcceptedLevels: acceptedLevels,
+		formatter:      &logrus.JSONFormatter{},
+	}
+}
+
+// Levels returns the set of log levels this hook responds to.
+// Required by the logrus.Hook interface.
+func (h *LokiHook) Levels() []logrus.Level {
+	return h.ctl.Levels(h.AcceptedLevels)
+}
+
+// IsEnabled reports whether the hook fires
+func (h *LokiHook) IsEnabled() bool {
+	return h.ctl.Enabled(!h.Disabled)
+}
+
+// SetEnabled enables or disables the hook, safely while it fires
+func (h *LokiHook) SetEnabled(enabled bool) {
+	h.ctl.SetEnabled(enabled)
+}
+
+// SetLevels changes the levels the hook accepts, safely while it fires
+func (h *LokiHook) SetLevels(levels []logrus.Level) {
+	h.ctl.SetLevels(levels)
+}
+
+// Fire queues the log entry for the next push.
+// Required by the logrus.Hook interface.
+func (h *LokiHook) Fire(entry *logrus.Entry) error {
+	if !h.IsEnabled() {
+		return nil
+	}
+
+	stream := h.streamLabels(entry)
+
+	// Values of label fields are already on the stream, no need to repeat them in the line
+	data := make(logrus.Fields, len(entry.Data))
+	for k, v := range entry.Data {
+		if !slices.Contains(h.LabelFields, k) {
+			data[k] = v
+		}
+	}
+	line, err := h.formatter.Format(&logrus.Entry{
+		Logger: entry.Logger, Data: data, Time: entry.Time, Level: entry.Level,
+		Caller: entry.Caller, Message: entry.Message, Context: entry.Context,
+	})
+	if err != nil {
+		fmt.Println("loki_log: unable to format log entry:", err)
+		return nil
+	}
+
+	le := lokiEntry{
+		labels: canonicalLabels(stream),
+		stream: stream,
+		time:   entry.Time,
+		line:   strings.TrimSuffix(string(line), "\n"),
+	}
+
+	if !h.batch().Add(le) {
+		fmt.Println("loki_log: queue full, dropping log entry")
+	}
+	return nil
+}
+
+// Flush sends queued entries, waiting for the push to complete or ctx to be done
+func (h *LokiHook) Flush(ctx context.Context) error {
+	return h.batch().Flush(ctx)
+}
+
+// Close sends queued entries and stops the sender
+func (h *LokiHook) Close() error {
+	return h.batch().Close(closeTimeout)
+}
+
+func (h *LokiHook) batch() *batch.Batcher[lokiEntry] {
+	h.batcherOnce.Do(func() { h.batcher = batch.New(h.BatchSize, h.BatchWait, h.push) })
+	return h.batcher
+}
+
+// pushRequest is the body of a push, see https://grafana.com/docs/loki/latest/reference/loki-http-api/#ingest-logs
+type pushRequest struct {
+	Streams []pushStream `json:"streams"`
+}
+
+type pushStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"` // [unix epoch in nanoseconds, log line]
+}
+
+// push sends the batch to Loki, retrying with backoff on network errors, 429 and 5xx responses
+func (h *LokiHook) push(entries []lokiEntry) {
+	body, err := encodeBatch(entries)
+	if err != nil {
+		fmt.Println("loki_log: unable to encode batch:", err)
+		return
+	}
+
+	backoff := batch.Backoff{MaxRetries: h.MaxRetries, Min: h.MinBackoff, Max: h.MaxBackoff}
+	err = backoff.Retry(h.batch(), func() (time.Duration, error) { return h.send(body) })
+	if err != nil {
+		fmt.Printf("loki_log: dropping %d entries: %v\n", len(entries), err)
+	}
+}
+
+// send posts the gzipped body. A negative retryAfter means the push must not be retried
+func (h *LokiHook) send(body []byte) (retryAfter time.Duration, err error) {
+	req, err := http.NewRequest(http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	if h.TenantID != "" {
+		req.Header.Set("X-Scope-OrgID", h.TenantID)
+	}
+	if h.Username != "" {
+		req.SetBasicAuth(h.Username, h.Password)
+	}
+
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	switch {
+	case resp.StatusCode < 300:
+		return 0, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return batch.RetryAfter(resp.Header.Get("Retry-After")), fmt.Errorf("loki returned %s: %s", resp.Status, respBody)
+	default:
+		return -1, fmt.Errorf("loki returned %s: %s", resp.Status, respBody)
+	}
+}
+
+// encodeBatch groups the batch into streams and returns the gzipped JSON push request
+func encodeBatch(entries []lokiEntry) ([]byte, error) {
+	var req pushRequest
+	streams := map[string]int{} // canonical labels -> index in req.Streams
+
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].time.Before(entries[j].time) })
+
+	for _, le := range entries {
+		i, ok := streams[le.labels]
+		if !ok {
+			i = len(req.Streams)
+			streams[le.labels] = i
+			req.Streams = append(req.Streams, pushStream{Stream: le.stream})
+		}
+		req.Streams[i].Values = append(req.Streams[i].Values,
+			[2]string{strconv.FormatInt(le.time.UnixNano(), 10), le.line})
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(gz).Encode(req); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// streamLabels returns the static labels plus those taken from the entry's fields
+func (h *LokiHook) streamLabels(entry *logrus.Entry) map[string]string {
+	stream := make(map[string]string, len(h.Labels)+len(h.LabelFields))
+	for k, v := range h.Labels {
+		stream[labelName(k)] = v
+	}
+
+	for _, field := range h.LabelFields {
+		if field == "level" {
+			stream["level"] = entry.Level.String()
+		} else if v, ok := entry.Data[field]; ok {
+			stream[labelName(field)] = fmt.Sprintf("%v", v)
+		}
+	}
+	if len(stream) == 0 {
+		stream[fallbackLabel] = filepath.Base(os.Args[0])
+	}
+	return stream
+}
+
+// canonicalLabels renders labels in Loki's selector form with sorted names, e.g. {level="info",service="api"}
+func canonicalLabels(labels map[string]string) string {
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+"="+strconv.Quote(labels[name]))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// labelName makes name a valid Loki label name: [a-zA-Z_][a-zA-Z0-9_]*
+func labelName(name string) string {
+	name = invalidLabelChars.ReplaceAllString(name, "_")
+	if name != "" && name[0] >= '0' && name[0] <= '9' {
+		name = "_" + name
+	}
+	return name
+}
+
+// pushURL appends the push path to a bare Loki address
+func pushURL(baseURL string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Path != "" && u.Path != "/") {
+		return baseURL
+	}
+	return strings.TrimSuffix(baseURL, "/") + PushPath
+}
@@ -0,0 +1,109 @@
+package otlp_log
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// encodeJSON encodes records as an ExportLogsServiceRequest in the OTLP JSON encoding:
+// lowerCamelCase keys, 64 bit integers as strings and trace / span ids as hex
+func (h *OTLPHook) encodeJSON(records []logRecord) ([]byte, error) {
+	resourceAttrs := make([]map[string]any, 0, len(h.ResourceAttributes))
+	for _, k := range sortedKeys(h.ResourceAttributes) {
+		resourceAttrs = append(resourceAttrs, jsonKeyValue(k, h.ResourceAttributes[k]))
+	}
+
+	logRecords := make([]map[string]any, 0, len(records))
+	for _, rec := range records {
+		attrs := make([]map[string]any, 0, len(rec.attributes))
+		for _, attr := range rec.attributes {
+			attrs = append(attrs, jsonKeyValue(attr.key, attr.value))
+		}
+
+		lr := map[string]any{
+			"timeUnixNano":         strconv.FormatInt(rec.time.UnixNano(), 10),
+			"observedTimeUnixNano": strconv.FormatInt(rec.observed.UnixNano(), 10),
+			"severityNumber":       rec.severity,
+			"severityText":         rec.severityText,
+			"body":                 map[string]any{"stringValue": rec.body},
+			"attributes":           attrs,
+		}
+		if rec.traceID != nil {
+			lr["traceId"] = hex.EncodeToString(rec.traceID)
+		}
+		if rec.spanID != nil {
+			lr["spanId"] = hex.EncodeToString(rec.spanID)
+		}
+		logRecords = append(logRecords, lr)
+	}
+
+	return json.Marshal(map[string]any{
+		"resourceLogs": []map[string]any{{
+			"resource": map[string]any{"attributes": resourceAttrs},
+			"scopeLogs": []map[string]any{{
+				"scope":      map[string]any{"name": scopeName},
+				"logRecords": logRecords,
+			}},
+		}},
+	})
+}
+
+func jsonKeyValue(key string, value any) map[string]any {
+	return map[string]any{"key": key, "value": jsonAnyValue(value)}
+}
+
+// jsonAnyValue converts v to an OTLP AnyValue
+func jsonAnyValue(v any) map[string]any {
+	switch val := v.(type) {
+	case nil:
+		return map[string]any{}
+	case string:
+		return map[string]any{"stringValue": val}
+	case bool:
+		return map[string]any{"boolValue": val}
+	case []byte:
+		return map[string]any{"bytesValue": val} // encoding/json base64 encodes it, as OTLP JSON expects
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"intValue": strconv.FormatInt(rv.Int(), 10)}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > math.MaxInt64 { // intValue is an int64, so keep the exact value as a string
+			return map[string]any{"stringValue": strconv.FormatUint(rv.Uint(), 10)}
+		}
+		return map[string]any{"intValue": strconv.FormatUint(rv.Uint(), 10)}
+	case reflect.Float32, reflect.Float64:
+		// JSON has no NaN or infinities, the protobuf JSON mapping spells them as strings
+		switch f := rv.Float(); {
+		case math.IsNaN(f):
+			return map[string]any{"doubleValue": "NaN"}
+		case math.IsInf(f, 1):
+			return map[string]any{"doubleValue": "Infinity"}
+		case math.IsInf(f, -1):
+			return map[string]any{"doubleValue": "-Infinity"}
+		default:
+			return map[string]any{"doubleValue": f}
+		}
+	case reflect.Slice, reflect.Array:
+		values := make([]map[string]any, 0, rv.Len())
+		for i := range rv.Len() {
+			values = append(values, jsonAnyValue(rv.Index(i).Interface()))
+		}
+		return map[string]any{"arrayValue": map[string]any{"values": values}}
+	case reflect.Map:
+		if rv.Type().Key().Kind() == reflect.String {
+			kvs := make([]map[string]any, 0, rv.Len())
+			for _, k := range sortedMapKeys(rv) {
+				kvs = append(kvs, jsonKeyValue(k, rv.MapIndex(reflect.ValueOf(k).Convert(rv.Type().Key())).Interface()))
+			}
+			return map[string]any{"kvlistValue": map[string]any{"values": kvs}}
+		}
+	}
+	return map[string]any{"stringValue": fmt.Sprintf("%v", v)}
+}
@@ -0,0 +1,298 @@
+package otlp_log
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rohanthewiz/logger/internal/batch"
+	"github.com/rohanthewiz/logger/internal/hookctl"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	LogsPath = "/v1/logs"
+
+	ProtocolJSON     = "http/json"
+	ProtocolProtobuf = "http/protobuf"
+
+	DefaultBatchSize  = 512
+	DefaultBatchWait  = time.Second
+	defaultMaxRetries = 5
+	defaultMinBackoff = 500 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+	closeTimeout      = 5 * time.Second
+
+	scopeName = "github.com/rohanthewiz/logger"
+
+	traceIDKey = "trace_id"
+	spanIDKey  = "span_id"
+)
+
+type severity struct {
+	number int
+	text   string
+}
+
+// severities maps logrus levels onto OpenTelemetry severity numbers and texts
+var severities = map[logrus.Level]severity{
+	logrus.TraceLevel: {1, "TRACE"},
+	logrus.DebugLevel: {5, "DEBUG"},
+	logrus.InfoLevel:  {9, "INFO"},
+	logrus.WarnLevel:  {13, "WARN"},
+	logrus.ErrorLevel: {17, "ERROR"},
+	logrus.FatalLevel: {21, "FATAL"},
+	logrus.PanicLevel: {24, "PANIC"}, // FATAL4, the most severe
+}
+
+// OTLPHook is a logrus hook that exports entries to an OpenTelemetry collector over OTLP/HTTP,
+// as JSON or protobuf. Entries are sent in batches; fields become log record attributes,
+// except trace_id and span_id (hex) which correlate the record with its trace
+type OTLPHook struct {
+	Endpoint           string            // e.g. http://otel-collector:4318/v1/logs
+	Protocol           string            // ProtocolJSON | ProtocolProtobuf
+	Headers            map[string]string // e.g. authentication headers
+	ResourceAttributes map[string]string // e.g. {"service.name": "billing"}
+	Gzip               bool              // compress requests
+	BatchSize          int               // records per request
+	BatchWait          time.Duration     // longest a record waits for its batch to fill
+	Backoff            batch.Backoff     // retries of failed exports
+	Client             *http.Client
+	AcceptedLevels     []logrus.Level // levels that trigger this hook
+	Disabled           bool           // allows the hook to be temporarily silenced
+
+	ctl         hookctl.Control // runtime changes of Disabled and AcceptedLevels
+	batcherOnce sync.Once
+	batcher     *batch.Batcher[logRecord] // created with the first entry, from BatchSize and BatchWait
+}
+
+// logRecord holds what is exported of an entry
+type logRecord struct {
+	time         time.Time
+	observed     time.Time
+	severity     int
+	severityText string
+	body         string
+	attributes   []attribute
+	traceID      []byte // 16 bytes when present
+	spanID       []byte // 8 bytes when present
+}
+
+type attribute struct {
+	key   string
+	value any
+}
+
+// NewOTLPHook creates an OTLPHook. baseURL may be the collector address (http://otel-collector:4318)
+// or the full logs URL. Call Close to export remaining records when done
+func NewOTLPHook(baseURL, protocol string, resourceAttributes map[string]string, acceptedLevels []logrus.Level) *OTLPHook {
+	return &OTLPHook{
+		Endpoint:           logsURL(baseURL),
+		Protocol:           protocol,
+		ResourceAttributes: resourceAttributes,
+		BatchSize:          DefaultBatchSize,
+		BatchWait:          DefaultBatchWait,
+		Backoff:            batch.Backoff{MaxRetries: defaultMaxRetries, Min: defaultMinBackoff, Max: defaultMaxBackoff},
+		Client:             &http.Client{Timeout: 10 * time.Second},
+		AcceptedLevels:     acceptedLevels,
+	}
+}
+
+// Levels returns the set of log levels this hook responds to.
+// Required by the logrus.Hook interface.
+func (h *OTLPHook) Levels() []logrus.Level {
+	return h.ctl.Levels(h.AcceptedLevels)
+}
+
+// IsEnabled reports whether the hook fires
+func (h *OTLPHook) IsEnabled() bool {
+	return h.ctl.Enabled(!h.Disabled)
+}
+
+// SetEnabled enables or disables the hook, safely while it fires
+func (h *OTLPHook) SetEnabled(enabled bool) {
+	h.ctl.SetEnabled(enabled)
+}
+
+// SetLevels changes the levels the hook accepts, safely while it fires
+func (h *OTLPHook) SetLevels(levels []logrus.Level) {
+	h.ctl.SetLevels(levels)
+}
+
+// Fire queues the log entry for the next export.
+// Required by the logrus.Hook interface.
+func (h *OTLPHook) Fire(entry *logrus.Entry) error {
+	if !h.IsEnabled() {
+		return nil
+	}
+
+	if !h.batch().Add(newLogRecord(entry)) {
+		fmt.Println("otlp_log: queue full, dropping log entry")
+	}
+	return nil
+}
+
+// Flush exports queued records, waiting for the export to complete or ctx to be done
+func (h *OTLPHook) Flush(ctx context.Context) error {
+	return h.batch().Flush(ctx)
+}
+
+// Close exports queued records and stops the exporter
+func (h *OTLPHook) Close() error {
+	return h.batch().Close(closeTimeout)
+}
+
+func (h *OTLPHook) batch() *batch.Batcher[logRecord] {
+	h.batcherOnce.Do(func() { h.batcher = batch.New(h.BatchSize, h.BatchWait, h.export) })
+	return h.batcher
+}
+
+func newLogRecord(entry *logrus.Entry) logRecord {
+	sev, ok := severities[entry.Level]
+	if !ok {
+		sev = severities[logrus.InfoLevel]
+	}
+
+	rec := logRecord{
+		time:         entry.Time,
+		observed:     time.Now(),
+		severity:     sev.number,
+		severityText: sev.text,
+		body:         entry.Message,
+	}
+
+	keys := make([]string, 0, len(entry.Data))
+	for k := range entry.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := entry.Data[k]
+		switch k {
+		case traceIDKey:
+			if id := hexID(v, 16); id != nil {
+				rec.traceID = id
+				continue
+			}
+		case spanIDKey:
+			if id := hexID(v, 8); id != nil {
+				rec.spanID = id
+				continue
+			}
+		}
+		rec.attributes = append(rec.attributes, attribute{key: k, value: v})
+	}
+	return rec
+}
+
+// hexID decodes v as a hex id of size bytes, returning nil if it isn't one (or is all zeros)
+func hexID(v any, size int) []byte {
+	s, ok := v.(string)
+	if !ok || len(s) != size*2 {
+		return nil
+	}
+	id, err := hex.DecodeString(s)
+	if err != nil || bytes.Count(id, []byte{0}) == size {
+		return nil
+	}
+	return id
+}
+
+// export sends the records, retrying with backoff on network errors and retryable statuses
+func (h *OTLPHook) export(records []logRecord) {
+	var body []byte
+	var err error
+	contentType := "application/json"
+
+	if h.Protocol == ProtocolProtobuf {
+		body, contentType = h.encodeProtobuf(records), "application/x-protobuf"
+	} else {
+		body, err = h.encodeJSON(records)
+	}
+	if err == nil && h.Gzip {
+		body, err = gzipBytes(body)
+	}
+	if err != nil {
+		fmt.Println("otlp_log: unable to encode records:", err)
+		return
+	}
+
+	err = h.Backoff.Retry(h.batch(), func() (time.Duration, error) { return h.send(body, contentType) })
+	if err != nil {
+		fmt.Printf("otlp_log: dropping %d records: %v\n", len(records), err)
+	}
+}
+
+// send posts the body. A negative retryAfter means the export must not be retried
+func (h *OTLPHook) send(body []byte, contentType string) (retryAfter time.Duration, err error) {
+	req, err := http.NewRequest(http.MethodPost, h.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if h.Gzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for k, v := range h.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusAccepted, http.StatusNoContent:
+		return 0, nil
+	// The statuses the OTLP specification lists as retryable
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return batch.RetryAfter(resp.Header.Get("Retry-After")),
+			fmt.Errorf("collector returned %s: %s", resp.Status, respBody)
+	default:
+		return -1, fmt.Errorf("collector returned %s: %s", resp.Status, respBody)
+	}
+}
+
+func gzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// logsURL appends the logs path to a bare collector address
+func logsURL(baseURL string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Path != "" && u.Path != "/") {
+		return baseURL
+	}
+	return strings.TrimSuffix(baseURL, "/") + LogsPath
+}
+
+// sortedKeys returns the keys of m in order, so requests are deterministic
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
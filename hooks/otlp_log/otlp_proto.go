@@ -0,0 +1,202 @@
+package otlp_log
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Field numbers of the OTLP protobuf messages used (opentelemetry/proto/logs/v1, common/v1)
+const (
+	fieldRequestResourceLogs = 1 // ExportLogsServiceRequest.resource_logs
+
+	fieldResourceLogsResource  = 1 // ResourceLogs.resource
+	fieldResourceLogsScopeLogs = 2 // ResourceLogs.scope_logs
+	fieldResourceAttributes    = 1 // Resource.attributes
+
+	fieldScopeLogsScope      = 1 // ScopeLogs.scope
+	fieldScopeLogsLogRecords = 2 // ScopeLogs.log_records
+	fieldScopeName           = 1 // InstrumentationScope.name
+
+	fieldRecordTime         = 1  // LogRecord.time_unix_nano, fixed64
+	fieldRecordSeverity     = 2  // LogRecord.severity_number
+	fieldRecordSeverityText = 3  // LogRecord.severity_text
+	fieldRecordBody         = 5  // LogRecord.body
+	fieldRecordAttributes   = 6  // LogRecord.attributes
+	fieldRecordTraceID      = 9  // LogRecord.trace_id
+	fieldRecordSpanID       = 10 // LogRecord.span_id
+	fieldRecordObservedTime = 11 // LogRecord.observed_time_unix_nano, fixed64
+
+	fieldKeyValueKey   = 1 // KeyValue.key
+	fieldKeyValueValue = 2 // KeyValue.value
+
+	fieldAnyString = 1 // AnyValue.string_value
+	fieldAnyBool   = 2 // AnyValue.bool_value
+	fieldAnyInt    = 3 // AnyValue.int_value
+	fieldAnyDouble = 4 // AnyValue.double_value
+	fieldAnyArray  = 5 // AnyValue.array_value
+	fieldAnyKVList = 6 // AnyValue.kvlist_value
+	fieldAnyBytes  = 7 // AnyValue.bytes_value
+	fieldValues    = 1 // ArrayValue.values, KeyValueList.values
+)
+
+// Protobuf wire types
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+// encodeProtobuf encodes records as an ExportLogsServiceRequest in the protobuf wire format
+func (h *OTLPHook) encodeProtobuf(records []logRecord) []byte {
+	var resource pbuf
+	for _, k := range sortedKeys(h.ResourceAttributes) {
+		resource.message(fieldResourceAttributes, pbKeyValue(k, h.ResourceAttributes[k]))
+	}
+
+	var scope pbuf
+	scope.string(fieldScopeName, scopeName)
+
+	var scopeLogs pbuf
+	scopeLogs.message(fieldScopeLogsScope, scope)
+	for _, rec := range records {
+		scopeLogs.message(fieldScopeLogsLogRecords, pbLogRecord(rec))
+	}
+
+	var resourceLogs pbuf
+	resourceLogs.message(fieldResourceLogsResource, resource)
+	resourceLogs.message(fieldResourceLogsScopeLogs, scopeLogs)
+
+	var req pbuf
+	req.message(fieldRequestResourceLogs, resourceLogs)
+	return req
+}
+
+func pbLogRecord(rec logRecord) pbuf {
+	var b pbuf
+	b.fixed64(fieldRecordTime, uint64(rec.time.UnixNano()))
+	b.varint(fieldRecordSeverity, uint64(rec.severity))
+	b.string(fieldRecordSeverityText, rec.severityText)
+
+	var body pbuf
+	body.string(fieldAnyString, rec.body)
+	b.message(fieldRecordBody, body)
+
+	for _, attr := range rec.attributes {
+		b.message(fieldRecordAttributes, pbKeyValue(attr.key, attr.value))
+	}
+	if rec.traceID != nil {
+		b.bytes(fieldRecordTraceID, rec.traceID)
+	}
+	if rec.spanID != nil {
+		b.bytes(fieldRecordSpanID, rec.spanID)
+	}
+	b.fixed64(fieldRecordObservedTime, uint64(rec.observed.UnixNano()))
+	return b
+}
+
+func pbKeyValue(key string, value any) pbuf {
+	var b pbuf
+	b.string(fieldKeyValueKey, key)
+	b.message(fieldKeyValueValue, pbAnyValue(value))
+	return b
+}
+
+// pbAnyValue converts v to an OTLP AnyValue
+func pbAnyValue(v any) pbuf {
+	var b pbuf
+	switch val := v.(type) {
+	case nil:
+		return b
+	case string:
+		b.string(fieldAnyString, val)
+		return b
+	case bool:
+		var i uint64
+		if val {
+			i = 1
+		}
+		b.varint(fieldAnyBool, i)
+		return b
+	case []byte:
+		b.bytes(fieldAnyBytes, val)
+		return b
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b.varint(fieldAnyInt, uint64(rv.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if rv.Uint() > math.MaxInt64 { // int_value is an int64, so keep the exact value as a string
+			b.string(fieldAnyString, strconv.FormatUint(rv.Uint(), 10))
+			break
+		}
+		b.varint(fieldAnyInt, rv.Uint())
+	case reflect.Float32, reflect.Float64:
+		b.fixed64(fieldAnyDouble, math.Float64bits(rv.Float()))
+	case reflect.Slice, reflect.Array:
+		var arr pbuf
+		for i := range rv.Len() {
+			arr.message(fieldValues, pbAnyValue(rv.Index(i).Interface()))
+		}
+		b.message(fieldAnyArray, arr)
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			b.string(fieldAnyString, fmt.Sprintf("%v", v))
+			break
+		}
+		var kvs pbuf
+		for _, k := range sortedMapKeys(rv) {
+			kvs.message(fieldValues, pbKeyValue(k, rv.MapIndex(reflect.ValueOf(k).Convert(rv.Type().Key())).Interface()))
+		}
+		b.message(fieldAnyKVList, kvs)
+	default:
+		b.string(fieldAnyString, fmt.Sprintf("%v", v))
+	}
+	return b
+}
+
+// sortedMapKeys returns the string keys of a map value in order
+func sortedMapKeys(rv reflect.Value) []string {
+	keys := make([]string, 0, rv.Len())
+	for _, k := range rv.MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// pbuf is a protobuf message being encoded
+type pbuf []byte
+
+func (b *pbuf) tag(field int, wireType int) {
+	*b = binary.AppendUvarint(*b, uint64(field)<<3|uint64(wireType))
+}
+
+func (b *pbuf) varint(field int, v uint64) {
+	b.tag(field, wireVarint)
+	*b = binary.AppendUvarint(*b, v)
+}
+
+func (b *pbuf) fixed64(field int, v uint64) {
+	b.tag(field, wireFixed64)
+	*b = binary.LittleEndian.AppendUint64(*b, v)
+}
+
+func (b *pbuf) bytes(field int, v []byte) {
+	b.tag(field, wireBytes)
+	*b = binary.AppendUvarint(*b, uint64(len(v)))
+	*b = append(*b, v...)
+}
+
+func (b *pbuf) string(field int, v string) {
+	b.bytes(field, []byte(v))
+}
+
+func (b *pbuf) message(field int, m pbuf) {
+	b.bytes(field, m)
+}
@@ -0,0 +1,308 @@
+package syslog_log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rohanthewiz/logger/internal/batch"
+	"github.com/rohanthewiz/logger/internal/hookctl"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultSDID          = "fields@32473" // 32473 is the private enterprise number reserved for examples (RFC 5612)
+	defaultRetryInterval = time.Second
+	writeTimeout         = 5 * time.Second
+	closeTimeout         = 5 * time.Second
+	batchSize            = 100
+	batchWait            = 100 * time.Millisecond
+	rfc5424Time          = "2006-01-02T15:04:05.000000Z07:00"
+	maxSDNameLen         = 32
+)
+
+// Facilities by name, as used in SyslogCfg
+var Facilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// severities maps logrus levels onto syslog severities
+var severities = map[logrus.Level]int{
+	logrus.PanicLevel: 1, // alert
+	logrus.FatalLevel: 2, // critical
+	logrus.ErrorLevel: 3, // error
+	logrus.WarnLevel:  4, // warning
+	logrus.InfoLevel:  6, // informational
+	logrus.DebugLevel: 7, // debug
+	logrus.TraceLevel: 7, // debug
+}
+
+// SyslogHook is a logrus hook that sends entries to a syslog server as RFC 5424 messages.
+// Fields are sent as structured data. On TCP and unix stream sockets messages are
+// octet-counted (RFC 6587). Entries are sent in the background; a failed connection
+// is re-established on a later entry. Call Close to send remaining entries when done
+type SyslogHook struct {
+	Network        string         // "udp" | "tcp" | "unix" (stream or datagram, whichever the socket is)
+	Address        string         // host:port, or the socket path for "unix"
+	Facility       int            // see Facilities
+	AppName        string         // APP-NAME of messages
+	Hostname       string         // HOSTNAME of messages
+	SDID           string         // SD-ID of the structured data element holding the fields
+	RetryInterval  time.Duration  // minimum wait between connection attempts
+	AcceptedLevels []logrus.Level // levels that trigger this hook
+	Disabled       bool           // allows the hook to be temporarily silenced
+
+	ctl         hookctl.Control // runtime changes of Disabled and AcceptedLevels
+	conn        net.Conn        // only used by the batcher's goroutine, like the fields below
+	framed      bool            // whether conn is a stream needing octet counting
+	lastAttempt time.Time       // of a failed connection attempt
+	batcherOnce sync.Once
+	batcher     *batch.Batcher[string]
+}
+
+// NewSyslogHook creates a SyslogHook. Pass an empty appName to use the program name
+func NewSyslogHook(network, address string, facility int, appName string, acceptedLevels []logrus.Level) *SyslogHook {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "-"
+	}
+	if appName == "" {
+		appName = filepath.Base(os.Args[0])
+	}
+
+	return &SyslogHook{
+		Network:        network,
+		Address:        address,
+		Facility:       facility,
+		AppName:        appName,
+		Hostname:       hostname,
+		SDID:           defaultSDID,
+		RetryInterval:  defaultRetryInterval,
+		AcceptedLevels: acceptedLevels,
+	}
+}
+
+// Levels returns the set of log levels this hook responds to.
+// Required by the logrus.Hook interface.
+func (h *SyslogHook) Levels() []logrus.Level {
+	return h.ctl.Levels(h.AcceptedLevels)
+}
+
+// IsEnabled reports whether the hook fires
+func (h *SyslogHook) IsEnabled() bool {
+	return h.ctl.Enabled(!h.Disabled)
+}
+
+// SetEnabled enables or disables the hook, safely while it fires
+func (h *SyslogHook) SetEnabled(enabled bool) {
+	h.ctl.SetEnabled(enabled)
+}
+
+// SetLevels changes the levels the hook accepts, safely while it fires
+func (h *SyslogHook) SetLevels(levels []logrus.Level) {
+	h.ctl.SetLevels(levels)
+}
+
+// Fire queues the log entry for the syslog server, so a server which is down doesn't block logging.
+// Required by the logrus.Hook interface.
+func (h *SyslogHook) Fire(entry *logrus.Entry) error {
+	if !h.IsEnabled() {
+		return nil
+	}
+
+	if !h.batch().Add(h.Format(entry)) {
+		fmt.Println("syslog_log: queue full, dropping log entry")
+	}
+	return nil
+}
+
+// Flush sends queued entries, waiting for the send to complete or ctx to be done
+func (h *SyslogHook) Flush(ctx context.Context) error {
+	return h.batch().Flush(ctx)
+}
+
+func (h *SyslogHook) batch() *batch.Batcher[string] {
+	h.batcherOnce.Do(func() { h.batcher = batch.New(batchSize, batchWait, h.send) })
+	return h.batcher
+}
+
+// send writes the messages, dropping the rest of them once one can't be sent or Close has timed out
+func (h *SyslogHook) send(msgs []string) {
+	for i, msg := range msgs {
+		select {
+		case <-h.batch().Abandoned():
+			fmt.Printf("syslog_log: dropping %d entries: %v\n", len(msgs)-i, batch.ErrAbandoned)
+			return
+		default:
+		}
+		if err := h.sendMsg(msg); err != nil {
+			fmt.Printf("syslog_log: dropping %d entries: %v\n", len(msgs)-i, err)
+			return
+		}
+	}
+}
+
+func (h *SyslogHook) sendMsg(msg string) (err error) {
+	// A write to a broken connection fails, so reconnect and try once more
+	for attempt := 0; attempt < 2; attempt++ {
+		if err = h.connect(); err != nil {
+			return fmt.Errorf("unable to connect to syslog: %w", err)
+		}
+		if err = h.write(msg); err == nil {
+			return nil
+		}
+		h.closeConn()
+	}
+	return fmt.Errorf("unable to send to syslog: %w", err)
+}
+
+// Format renders the entry as an RFC 5424 message (without framing)
+func (h *SyslogHook) Format(entry *logrus.Entry) string {
+	sev, ok := severities[entry.Level]
+	if !ok {
+		sev = severities[logrus.InfoLevel]
+	}
+
+	// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+	return fmt.Sprintf("<%d>1 %s %s %s %d - %s %s",
+		h.Facility*8+sev,
+		entry.Time.Format(rfc5424Time),
+		headerField(h.Hostname, 255),
+		headerField(h.AppName, 48),
+		os.Getpid(),
+		h.structuredData(entry.Data),
+		entry.Message,
+	)
+}
+
+// structuredData renders the fields as a single SD-ELEMENT, e.g. [fields@32473 user="jane" id="42"]
+func (h *SyslogHook) structuredData(data logrus.Fields) string {
+	if len(data) == 0 {
+		return "-"
+	}
+
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString("[" + sdName(h.SDID))
+	for _, k := range keys {
+		name := sdName(k)
+		if name == "" {
+			continue
+		}
+		sb.WriteString(" " + name + `="` + sdValue(fmt.Sprintf("%v", data[k])) + `"`)
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
+
+// Close sends queued entries, stops the sender and closes the connection to the syslog server
+func (h *SyslogHook) Close() error {
+	if err := h.batch().Close(closeTimeout); err != nil {
+		// The sender may still be writing, so the connection is closed once it stops
+		go func() {
+			<-h.batch().Done()
+			h.closeConn()
+		}()
+		return err
+	}
+	h.closeConn() // the sender has stopped, so the connection is ours
+	return nil
+}
+
+// connect dials the server unless already connected, at most once per RetryInterval
+func (h *SyslogHook) connect() error {
+	if h.conn != nil {
+		return nil
+	}
+	if time.Since(h.lastAttempt) < h.RetryInterval {
+		return errors.New("waiting to reconnect")
+	}
+
+	conn, framed, err := h.dial()
+	if err != nil {
+		h.lastAttempt = time.Now()
+		return err
+	}
+
+	h.conn, h.framed, h.lastAttempt = conn, framed, time.Time{}
+	return nil
+}
+
+func (h *SyslogHook) dial() (conn net.Conn, framed bool, err error) {
+	switch h.Network {
+	case "unix":
+		// Local syslog daemons (/dev/log) usually listen on datagram sockets
+		if conn, err = net.DialTimeout("unixgram", h.Address, writeTimeout); err == nil {
+			return conn, false, nil
+		}
+		conn, err = net.DialTimeout("unix", h.Address, writeTimeout)
+		return conn, true, err
+	case "udp", "udp4", "udp6", "unixgram":
+		conn, err = net.DialTimeout(h.Network, h.Address, writeTimeout)
+		return conn, false, err
+	default: // tcp
+		conn, err = net.DialTimeout(h.Network, h.Address, writeTimeout)
+		return conn, true, err
+	}
+}
+
+func (h *SyslogHook) write(msg string) error {
+	if h.framed {
+		msg = fmt.Sprintf("%d %s", len(msg), msg) // octet counting (RFC 6587)
+	}
+	_ = h.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	_, err := h.conn.Write([]byte(msg))
+	return err
+}
+
+func (h *SyslogHook) closeConn() {
+	if h.conn != nil {
+		_ = h.conn.Close()
+		h.conn = nil
+	}
+}
+
+// headerField returns s made safe for a header field: printable ASCII without spaces, "-" if empty
+func headerField(s string, maxLen int) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, s)
+	if s == "" {
+		return "-"
+	}
+	return s[:min(len(s), maxLen)]
+}
+
+// sdName returns s made safe for an SD-NAME: printable ASCII except '=', ' ', ']' and '"'
+func sdName(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' || r == '=' || r == ']' || r == '"' {
+			return '_'
+		}
+		return r
+	}, s)
+	return s[:min(len(s), maxSDNameLen)]
+}
+
+// sdValue escapes '"', '\' and ']' in a PARAM-VALUE
+func sdValue(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(s)
+}
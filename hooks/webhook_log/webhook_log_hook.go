@@ -0,0 +1,249 @@
+package webhook_log
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/rohanthewiz/logger/internal/batch"
+	"github.com/rohanthewiz/logger/internal/hookctl"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultSignatureHeader = "X-Signature-256"
+	defaultTimeout         = 10 * time.Second
+	defaultMaxRetries      = 5
+	defaultMinBackoff      = 500 * time.Millisecond
+	defaultMaxBackoff      = 30 * time.Second
+	closeTimeout           = 5 * time.Second
+)
+
+// defaultBackoff is used when a hook has no Backoff set, e.g. when built without NewWebhookHook
+var defaultBackoff = batch.Backoff{MaxRetries: defaultMaxRetries, Min: defaultMinBackoff, Max: defaultMaxBackoff}
+
+// TemplateData is what the body template is executed with. Example template:
+//
+//	{"summary": {{json .Message}}, "severity": "{{.Level}}", "service": {{json (index .Fields "service")}}}
+type TemplateData struct {
+	Time    time.Time
+	Level   string
+	Message string
+	Fields  map[string]any
+}
+
+// TemplateFuncs are available to body templates:
+// json renders a value as JSON (quoting and escaping strings), upper and lower change case,
+// rfc3339 formats a time
+var TemplateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"upper":   strings.ToUpper,
+	"lower":   strings.ToLower,
+	"rfc3339": func(t time.Time) string { return t.Format(time.RFC3339Nano) },
+}
+
+// WebhookHook is a logrus hook that sends entries to an HTTP endpoint.
+// The body is rendered from Template (JSON of TemplateData if nil). With a Secret
+// the body is signed with HMAC-SHA256 in SignatureHeader as "sha256=<hex>".
+// Requests are sent one at a time in the background; failed ones are retried with backoff
+// on network errors, 429 and 5xx responses
+type WebhookHook struct {
+	URL             string
+	Method          string             // defaults to POST
+	Template        *template.Template // renders the body from TemplateData
+	ContentType     string             // defaults to application/json
+	Headers         map[string]string  // e.g. authentication headers
+	Secret          string             // HMAC-SHA256 key for signing bodies
+	SignatureHeader string             // defaults to DefaultSignatureHeader
+	Client          *http.Client
+	Backoff         batch.Backoff  // retries of failed requests
+	AcceptedLevels  []logrus.Level // levels that trigger this hook
+	Disabled        bool           // allows the hook to be temporarily silenced
+
+	ctl         hookctl.Control // runtime changes of Disabled and AcceptedLevels
+	batcherOnce sync.Once
+	batcher     *batch.Batcher[[]byte] // created with the first entry
+}
+
+// ParseTemplate parses a body template, with TemplateFuncs available
+func ParseTemplate(bodyTemplate string) (*template.Template, error) {
+	tmpl, err := template.New("webhook").Funcs(TemplateFuncs).Parse(bodyTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("webhook_log: invalid body template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// NewWebhookHook creates a WebhookHook, parsing bodyTemplate if given.
+// Call Close to send remaining entries when done
+func NewWebhookHook(url, bodyTemplate string, acceptedLevels []logrus.Level) (*WebhookHook, error) {
+	h := &WebhookHook{
+		URL:             url,
+		Method:          http.MethodPost,
+		ContentType:     "application/json",
+		SignatureHeader: DefaultSignatureHeader,
+		Client:          &http.Client{Timeout: defaultTimeout},
+		Backoff:         defaultBackoff,
+		AcceptedLevels:  acceptedLevels,
+	}
+
+	if bodyTemplate != "" {
+		tmpl, err := ParseTemplate(bodyTemplate)
+		if err != nil {
+			return nil, err
+		}
+		h.Template = tmpl
+	}
+	return h, nil
+}
+
+// Levels returns the set of log levels this hook responds to.
+// Required by the logrus.Hook interface.
+func (h *WebhookHook) Levels() []logrus.Level {
+	return h.ctl.Levels(h.AcceptedLevels)
+}
+
+// IsEnabled reports whether the hook fires
+func (h *WebhookHook) IsEnabled() bool {
+	return h.ctl.Enabled(!h.Disabled)
+}
+
+// SetEnabled enables or disables the hook, safely while it fires
+func (h *WebhookHook) SetEnabled(enabled bool) {
+	h.ctl.SetEnabled(enabled)
+}
+
+// SetLevels changes the levels the hook accepts, safely while it fires
+func (h *WebhookHook) SetLevels(levels []logrus.Level) {
+	h.ctl.SetLevels(levels)
+}
+
+// Fire renders the body and queues it to be sent in the background.
+// Required by the logrus.Hook interface.
+func (h *WebhookHook) Fire(entry *logrus.Entry) error {
+	if !h.IsEnabled() {
+		return nil
+	}
+
+	body, err := h.Body(entry)
+	if err != nil {
+		fmt.Println("webhook_log: unable to render body:", err)
+		return nil // don't propagate rendering errors to logrus
+	}
+
+	if !h.batch().Add(body) {
+		fmt.Println("webhook_log: queue full, dropping log entry")
+	}
+	return nil
+}
+
+// Body renders the request body for entry
+func (h *WebhookHook) Body(entry *logrus.Entry) ([]byte, error) {
+	data := TemplateData{
+		Time:    entry.Time,
+		Level:   entry.Level.String(),
+		Message: entry.Message,
+		Fields:  make(map[string]any, len(entry.Data)),
+	}
+	for k, v := range entry.Data {
+		data.Fields[k] = v
+	}
+
+	if h.Template == nil {
+		return json.Marshal(data)
+	}
+
+	var buf bytes.Buffer
+	if err := h.Template.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Sign returns the signature of body: "sha256=" and the hex HMAC-SHA256 of body keyed with secret
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Flush sends queued entries, waiting for them to be delivered or ctx to be done
+func (h *WebhookHook) Flush(ctx context.Context) error {
+	return h.batch().Flush(ctx)
+}
+
+// Close sends queued entries and stops the sender
+func (h *WebhookHook) Close() error {
+	return h.batch().Close(closeTimeout)
+}
+
+// batch returns the queue of bodies, sent one at a time
+func (h *WebhookHook) batch() *batch.Batcher[[]byte] {
+	h.batcherOnce.Do(func() { h.batcher = batch.New(1, time.Second, h.post) })
+	return h.batcher
+}
+
+// post sends the bodies, retrying each with backoff
+func (h *WebhookHook) post(bodies [][]byte) {
+	backoff := h.Backoff
+	if backoff == (batch.Backoff{}) {
+		backoff = defaultBackoff
+	}
+
+	for _, body := range bodies {
+		err := backoff.Retry(h.batch(), func() (time.Duration, error) { return h.send(body) })
+		if err != nil {
+			fmt.Printf("webhook_log: dropping entry for %s: %v\n", h.URL, err)
+		}
+	}
+}
+
+// send makes the request. A negative retryAfter means it must not be retried
+func (h *WebhookHook) send(body []byte) (retryAfter time.Duration, err error) {
+	req, err := http.NewRequest(h.Method, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Content-Type", h.ContentType)
+	for k, v := range h.Headers {
+		req.Header.Set(k, v)
+	}
+	if h.Secret != "" {
+		req.Header.Set(h.SignatureHeader, Sign(h.Secret, body))
+	}
+
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 300 {
+		return 0, nil
+	}
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	err = fmt.Errorf("webhook returned %s: %s", resp.Status, respBody)
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return batch.RetryAfter(resp.Header.Get("Retry-After")), err
+	case resp.StatusCode >= 500:
+		return 0, err
+	default:
+		return -1, err
+	}
+}
@@ -0,0 +1,250 @@
+// Package batch queues items and hands them to a send function in batches,
+// for hooks which deliver entries in the background
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const defaultQueueSize = 10000
+
+// ErrAbandoned is returned by Retry once the Batcher's Close has timed out
+var ErrAbandoned = errors.New("not sent before the close timeout")
+
+// Batcher collects items into batches, sending when a batch reaches size, has waited wait,
+// or is flushed
+type Batcher[T any] struct {
+	size        int           // items per batch
+	wait        time.Duration // longest an item waits for its batch to fill
+	send        func(batch []T)
+	queue       chan T
+	flushReq    chan chan struct{}
+	stop        chan struct{}
+	abandoned   chan struct{} // closed when Close times out, so remaining items are dropped
+	done        chan struct{}
+	startOnce   sync.Once
+	closeOnce   sync.Once
+	abandonOnce sync.Once
+}
+
+// New creates a Batcher which calls send with each batch, from a single goroutine
+func New[T any](size int, wait time.Duration, send func(batch []T)) *Batcher[T] {
+	return &Batcher[T]{
+		size:      size,
+		wait:      wait,
+		send:      send,
+		queue:     make(chan T, defaultQueueSize),
+		flushReq:  make(chan chan struct{}),
+		stop:      make(chan struct{}),
+		abandoned: make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Add queues item without blocking. It returns false if the queue is full.
+// Items added once the Batcher is closed are dropped
+func (b *Batcher[T]) Add(item T) bool {
+	b.start()
+
+	select {
+	case <-b.done:
+		return true
+	default:
+	}
+
+	select {
+	case b.queue <- item:
+		return true
+	default:
+		return false
+	}
+}
+
+// Flush sends the items queued so far, waiting for the send to complete or ctx to be done
+func (b *Batcher[T]) Flush(ctx context.Context) error {
+	b.start()
+
+	reply := make(chan struct{})
+	select {
+	case b.flushReq <- reply:
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("flush: %w", ctx.Err())
+	}
+
+	select {
+	case <-reply:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("flush: %w", ctx.Err())
+	}
+}
+
+// Close sends the queued items and stops the sender, waiting for it up to timeout.
+// Past timeout the Batcher is abandoned: Retry gives up and the items left are dropped
+func (b *Batcher[T]) Close(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	err := b.Flush(ctx)
+	b.closeOnce.Do(func() { close(b.stop) })
+
+	select {
+	case <-b.done:
+		return err
+	case <-ctx.Done():
+	}
+
+	select {
+	case <-b.done: // finished as the deadline passed
+		return err
+	default:
+		b.abandonOnce.Do(func() { close(b.abandoned) })
+		return fmt.Errorf("close: %w", ctx.Err())
+	}
+}
+
+// Stopping is closed when the Batcher is being closed, so a send can stop retrying
+func (b *Batcher[T]) Stopping() <-chan struct{} {
+	return b.stop
+}
+
+// Abandoned is closed when Close has timed out, so a send must not be attempted any more
+func (b *Batcher[T]) Abandoned() <-chan struct{} {
+	return b.abandoned
+}
+
+// Done is closed once the sender has stopped, which may be after Close has timed out
+func (b *Batcher[T]) Done() <-chan struct{} {
+	return b.done
+}
+
+// Stopper is what Retry needs of a Batcher
+type Stopper interface {
+	Stopping() <-chan struct{}
+	Abandoned() <-chan struct{}
+}
+
+func (b *Batcher[T]) start() {
+	b.startOnce.Do(func() { go b.run() })
+}
+
+func (b *Batcher[T]) run() {
+	defer close(b.done)
+
+	var batch []T
+	timer := time.NewTimer(b.wait)
+	timer.Stop()
+
+	add := func(item T) {
+		if len(batch) == 0 {
+			timer.Reset(b.wait)
+		}
+		// Once abandoned the items left are collected, so a single send reports what is dropped
+		if batch = append(batch, item); len(batch) >= b.size && !abandoned(b) {
+			b.sendBatch(&batch, timer)
+		}
+	}
+
+	for {
+		if abandoned(b) {
+			b.drain(add)
+			b.sendBatch(&batch, timer)
+			return
+		}
+
+		select {
+		case item := <-b.queue:
+			add(item)
+		case <-timer.C:
+			b.sendBatch(&batch, timer)
+		case reply := <-b.flushReq:
+			b.drain(add)
+			b.sendBatch(&batch, timer)
+			close(reply)
+		case <-b.stop:
+			b.drain(add)
+			b.sendBatch(&batch, timer)
+			return
+		}
+	}
+}
+
+func (b *Batcher[T]) sendBatch(batch *[]T, timer *time.Timer) {
+	timer.Stop()
+	if len(*batch) > 0 {
+		b.send(*batch)
+		*batch = nil
+	}
+}
+
+// drain adds the items queued so far
+func (b *Batcher[T]) drain(add func(T)) {
+	for {
+		select {
+		case item := <-b.queue:
+			add(item)
+		default:
+			return
+		}
+	}
+}
+
+// Backoff configures retries of a send
+type Backoff struct {
+	MaxRetries int
+	Min        time.Duration
+	Max        time.Duration
+}
+
+// Retry calls send until it succeeds, returns a negative retryAfter (a permanent failure),
+// or MaxRetries is reached, waiting with exponential backoff (or retryAfter, if longer) in between.
+// Once the Batcher is stopping it makes one last attempt; once abandoned it returns ErrAbandoned
+// without attempting
+func (bo Backoff) Retry(b Stopper, send func() (retryAfter time.Duration, err error)) error {
+	wait := bo.Min
+	for attempt := 0; ; attempt++ {
+		if abandoned(b) {
+			return ErrAbandoned
+		}
+		retryAfter, err := send()
+		if err == nil || retryAfter < 0 || attempt >= bo.MaxRetries {
+			return err
+		}
+
+		select {
+		case <-time.After(max(wait, retryAfter)):
+		case <-b.Stopping():
+			if abandoned(b) {
+				return ErrAbandoned
+			}
+			_, err = send()
+			return err
+		}
+		wait = min(wait*2, bo.Max)
+	}
+}
+
+func abandoned(b Stopper) bool {
+	select {
+	case <-b.Abandoned():
+		return true
+	default:
+		return false
+	}
+}
+
+// RetryAfter parses a Retry-After header given in seconds, returning 0 if absent or invalid
+func RetryAfter(header string) time.Duration {
+	secs, err := strconv.Atoi(header)
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
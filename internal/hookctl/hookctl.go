@@ -0,0 +1,57 @@
+// Package hookctl holds the state of a hook which can be changed at runtime
+// (e.g. by the logger's admin handler) while the hook fires on other goroutines
+package hookctl
+
+import (
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	unset int32 = iota
+	enabled
+	disabled
+)
+
+// Control is the runtime enabled state and levels of a hook. Until they are set,
+// the hook's configured values (its Disabled and AcceptedLevels fields) apply.
+// The zero value is ready to use
+type Control struct {
+	state  atomic.Int32
+	levels atomic.Pointer[[]logrus.Level]
+}
+
+// Enabled returns the state set by SetEnabled, or configured if it was never set
+func (c *Control) Enabled(configured bool) bool {
+	switch c.state.Load() {
+	case enabled:
+		return true
+	case disabled:
+		return false
+	default:
+		return configured
+	}
+}
+
+// SetEnabled enables or disables the hook
+func (c *Control) SetEnabled(on bool) {
+	if on {
+		c.state.Store(enabled)
+	} else {
+		c.state.Store(disabled)
+	}
+}
+
+// Levels returns the levels set by SetLevels, or configured if they were never set
+func (c *Control) Levels(configured []logrus.Level) []logrus.Level {
+	if levels := c.levels.Load(); levels != nil {
+		return *levels
+	}
+	return configured
+}
+
+// SetLevels sets the levels the hook accepts
+func (c *Control) SetLevels(levels []logrus.Level) {
+	c.levels.Store(&levels)
+}
@@ -3,31 +3,40 @@ package logger
 import "github.com/sirupsen/logrus"
 
 type loggerLevels struct {
-	Debug, Info, Warn, Error string
+	Trace, Debug, Info, Warn, Error, Fatal, Panic string
 }
 
 // Consumable log levels (convenience var)
 var LogLevel = loggerLevels{
+	Panic: "panic",
+	Fatal: "fatal",
 	Error: "error",
 	Warn:  "warn",
 	Info:  "info",
 	Debug: "debug",
+	Trace: "trace",
 }
 
 const (
+	StrLevelPanic = "panic"
+	StrLevelFatal = "fatal"
 	StrLevelError = "error"
 	StrLevelWarn  = "warn"
 	StrLevelInfo  = "info"
 	StrLevelDebug = "debug"
+	StrLevelTrace = "trace"
 )
 
 // Internal only - keep private
 var logrusLevels = map[string]logrus.Level{
-	"debug": logrus.DebugLevel,
-	"info":  logrus.InfoLevel,
-	"warn":  logrus.WarnLevel,
-	"error": logrus.ErrorLevel,
-	"fatal": logrus.FatalLevel,
+	"trace":   logrus.TraceLevel,
+	"debug":   logrus.DebugLevel,
+	"info":    logrus.InfoLevel,
+	"warn":    logrus.WarnLevel,
+	"warning": logrus.WarnLevel,
+	"error":   logrus.ErrorLevel,
+	"fatal":   logrus.FatalLevel,
+	"panic":   logrus.PanicLevel,
 }
 
 // AllowedLevels returns all log levels at or above the specified level
@@ -0,0 +1,261 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AdminLevel is the body of the admin level endpoints
+type AdminLevel struct {
+	Level string `json:"level"`
+}
+
+// AdminHook describes a hook in the admin hook endpoints.
+// For updates (PUT), empty / nil fields are left unchanged
+type AdminHook struct {
+	Name    string `json:"name"`
+	Type    string `json:"type,omitempty"`
+	Level   string `json:"level,omitempty"` // least severe level accepted by the hook
+	Enabled *bool  `json:"enabled,omitempty"`
+}
+
+// AdminHandler returns an http.Handler to view and change the default Logger's configuration at runtime
+// See (*Logger).AdminHandler
+func AdminHandler() http.Handler {
+	return defaultLogger.AdminHandler()
+}
+
+// AdminHandler returns an http.Handler to view and change the Logger's configuration at runtime.
+// Routes (relative to where the handler is mounted):
+//
+//	GET /level          - the global log level
+//	PUT /level          - set the global log level, body: {"level": "debug"}
+//	GET /hooks          - list the hooks configured via LogConfig
+//	GET /hooks/{name}   - a single hook, e.g. "slack_api"
+//	PUT /hooks/{name}   - set a hook's level and/or enable / disable it, body: {"level": "error", "enabled": false}
+//
+// The handler does no authentication, so mount it on an internal listener or behind your own auth
+// Example:
+//
+//	http.Handle("/admin/log/", http.StripPrefix("/admin/log", logger.AdminHandler()))
+func (l *Logger) AdminHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /level", l.adminGetLevel)
+	mux.HandleFunc("PUT /level", l.adminPutLevel)
+	mux.HandleFunc("GET /hooks", l.adminListHooks)
+	mux.HandleFunc("GET /hooks/{name}", l.adminGetHook)
+	mux.HandleFunc("PUT /hooks/{name}", l.adminPutHook)
+	return mux
+}
+
+func (l *Logger) adminGetLevel(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, AdminLevel{Level: l.GetLogLevel()})
+}
+
+func (l *Logger) adminPutLevel(w http.ResponseWriter, r *http.Request) {
+	var body AdminLevel
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, ok := logrusLevels[strings.ToLower(body.Level)]; !ok {
+		http.Error(w, fmt.Sprintf("unknown level %q", body.Level), http.StatusBadRequest)
+		return
+	}
+
+	l.SetLogLevel(body.Level)
+	l.Info("Log level changed via admin handler", "new_level", l.GetLogLevel())
+
+	l.adminGetLevel(w, r)
+}
+
+func (l *Logger) adminListHooks(w http.ResponseWriter, _ *http.Request) {
+	l.cfgMu.Lock()
+	defer l.cfgMu.Unlock()
+
+	hooks := make([]AdminHook, 0, len(l.hooks))
+	for _, mh := range l.hooks {
+		hooks = append(hooks, adminHookFrom(mh))
+	}
+	writeJSON(w, hooks)
+}
+
+func (l *Logger) adminGetHook(w http.ResponseWriter, r *http.Request) {
+	l.cfgMu.Lock()
+	defer l.cfgMu.Unlock()
+
+	mh, ok := findManagedHook(l.hooks, r.PathValue("name"))
+	if !ok {
+		http.Error(w, fmt.Sprintf("hook %q not configured", r.PathValue("name")), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, adminHookFrom(mh))
+}
+
+func (l *Logger) adminPutHook(w http.ResponseWriter, r *http.Request) {
+	var body AdminHook
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "invalid body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	l.cfgMu.Lock()
+	defer l.cfgMu.Unlock()
+
+	name := r.PathValue("name")
+	mh, ok := findManagedHook(l.hooks, name)
+	if !ok {
+		http.Error(w, fmt.Sprintf("hook %q not configured", name), http.StatusNotFound)
+		return
+	}
+
+	rh, ok := mh.hook.(RuntimeHook)
+	if !ok && (body.Level != "" || body.Enabled != nil) {
+		http.Error(w, fmt.Sprintf("hook %q cannot be changed at runtime", name), http.StatusBadRequest)
+		return
+	}
+
+	// Validate everything before changing anything
+	var levels []logrus.Level
+	if body.Level != "" {
+		lvl, ok := logrusLevels[strings.ToLower(body.Level)]
+		if !ok {
+			http.Error(w, fmt.Sprintf("unknown level %q", body.Level), http.StatusBadRequest)
+			return
+		}
+		levels = AllowedLevels(lvl)
+	}
+
+	if body.Level != "" {
+		rh.SetLevels(levels)
+		l.setManagedHookLevel(name, strings.ToLower(body.Level))
+		if strings.HasPrefix(name, outputHookPrefix) {
+			l.setLogLevel(l.GetLogLevel()) // the logrus level follows the most verbose output
+		}
+
+		// logrus indexes hooks by level when they are added, so re-register them.
+		// cfgMu is held, so the hooks can't be replaced while they are read
+		levelHooks := make(logrus.LevelHooks)
+		for _, hook := range uniqueHooks(l.lr.Hooks) {
+			levelHooks.Add(hook)
+		}
+		l.lr.ReplaceHooks(levelHooks)
+	}
+	if body.Enabled != nil {
+		rh.SetEnabled(*body.Enabled)
+	}
+
+	l.Info("Hook changed via admin handler", "hook", name)
+	writeJSON(w, adminHookFrom(mh))
+}
+
+// setManagedHookLevel records a new level for the named hook in the applied config,
+// so a later Reconfigure compares against what is actually running
+func (l *Logger) setManagedHookLevel(name, level string) {
+	for i := range l.hooks {
+		if l.hooks[i].name != name {
+			continue
+		}
+		switch cfg := l.hooks[i].cfg.(type) {
+		case TeamsLogCfg:
+			cfg.LogLevel = level
+			l.hooks[i].cfg, l.cfg.TeamsLogCfg = cfg, cfg
+		case SlackAPICfg:
+			cfg.LogLevel = level
+			l.hooks[i].cfg, l.cfg.SlackAPICfg = cfg, cfg
+		case LogChanCfg:
+			cfg.LogLevel = level
+			l.hooks[i].cfg, l.cfg.LogChanCfg = cfg, cfg
+		case FileLogCfg:
+			cfg.LogLevel = level
+			l.hooks[i].cfg, l.cfg.FileLogCfg = cfg, cfg
+		case SyslogCfg:
+			cfg.LogLevel = level
+			l.hooks[i].cfg, l.cfg.SyslogCfg = cfg, cfg
+		case JournaldCfg:
+			cfg.LogLevel = level
+			l.hooks[i].cfg, l.cfg.JournaldCfg = cfg, cfg
+		case GELFCfg:
+			cfg.LogLevel = level
+			l.hooks[i].cfg, l.cfg.GELFCfg = cfg, cfg
+		case LokiCfg:
+			cfg.LogLevel = level
+			l.hooks[i].cfg, l.cfg.LokiCfg = cfg, cfg
+		case OTLPCfg:
+			cfg.LogLevel = level
+			l.hooks[i].cfg, l.cfg.OTLPCfg = cfg, cfg
+		case ElasticCfg:
+			cfg.LogLevel = level
+			l.hooks[i].cfg, l.cfg.ElasticCfg = cfg, cfg
+		case FluentCfg:
+			cfg.LogLevel = level
+			l.hooks[i].cfg, l.cfg.FluentCfg = cfg, cfg
+		case WebhookCfg:
+			cfg.LogLevel = level
+			l.hooks[i].cfg = cfg
+			l.cfg.Webhooks = slices.Clone(l.cfg.Webhooks) // the previous slice may be shared with an earlier config
+			for j, spec := range webhookSpecs(l.cfg.Webhooks) {
+				if spec.name == name {
+					l.cfg.Webhooks[j] = cfg
+				}
+			}
+		case OutputCfg:
+			cfg.LogLevel = level
+			l.hooks[i].cfg = cfg
+			l.cfg.Outputs = slices.Clone(l.cfg.Outputs) // the previous slice may be shared with an earlier config
+			for j, spec := range outputSpecs(l.cfg.Outputs) {
+				if spec.name == name {
+					l.cfg.Outputs[j] = cfg
+				}
+			}
+		}
+	}
+}
+
+func adminHookFrom(mh managedHook) AdminHook {
+	ah := AdminHook{
+		Name:  mh.name,
+		Type:  fmt.Sprintf("%T", mh.hook),
+		Level: leastSevereLevel(mh.hook.Levels()),
+	}
+	if rh, ok := mh.hook.(RuntimeHook); ok {
+		enabled := rh.IsEnabled()
+		ah.Enabled = &enabled
+	}
+	return ah
+}
+
+// leastSevereLevel returns the most verbose of levels as a string
+func leastSevereLevel(levels []logrus.Level) string {
+	if len(levels) == 0 {
+		return ""
+	}
+	lvl := levels[0]
+	for _, l := range levels[1:] {
+		if l > lvl {
+			lvl = l
+		}
+	}
+	return lvl.String()
+}
+
+// RuntimeHook is implemented by hooks whose enabled state and levels can be changed
+// while they fire, e.g. by the admin handler
+type RuntimeHook interface {
+	logrus.Hook
+	IsEnabled() bool
+	SetEnabled(enabled bool)
+	SetLevels(levels []logrus.Level)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		fmt.Println("Error writing admin response:", err)
+	}
+}
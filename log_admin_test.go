@@ -0,0 +1,139 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAdminHandler(t *testing.T) {
+	logCh := make(chan string, 10)
+	lgr := New(LogConfig{
+		LogLevel:   "info",
+		LogChanCfg: LogChanCfg{Enabled: true, Ch: logCh, LogLevel: "warn"},
+	})
+	defer lgr.Close()
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+
+	srv := httptest.NewServer(http.StripPrefix("/admin/log", lgr.AdminHandler()))
+	defer srv.Close()
+
+	do := func(method, path, body string, out any) int {
+		t.Helper()
+		req, err := http.NewRequest(method, srv.URL+"/admin/log"+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if out != nil && resp.StatusCode == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return resp.StatusCode
+	}
+
+	var lvl AdminLevel
+	if code := do("PUT", "/level", `{"level":"debug"}`, &lvl); code != http.StatusOK || lvl.Level != "debug" {
+		t.Errorf("PUT /level = %d %+v", code, lvl)
+	}
+	if lgr.Logrus().GetLevel().String() != "debug" {
+		t.Errorf("expected the logger level to be debug, got %s", lgr.Logrus().GetLevel())
+	}
+	if code := do("PUT", "/level", `{"level":"verbose"}`, nil); code != http.StatusBadRequest {
+		t.Errorf("expected a bad request for an unknown level, got %d", code)
+	}
+
+	var hooks []AdminHook
+	if code := do("GET", "/hooks", "", &hooks); code != http.StatusOK || len(hooks) != 1 ||
+		hooks[0].Name != "log_chan" || hooks[0].Level != "warning" || !*hooks[0].Enabled {
+		t.Errorf("GET /hooks = %d %+v", code, hooks)
+	}
+
+	var hook AdminHook
+	if code := do("PUT", "/hooks/log_chan", `{"level":"debug"}`, &hook); code != http.StatusOK || hook.Level != "debug" {
+		t.Errorf("PUT /hooks/log_chan = %d %+v", code, hook)
+	}
+	for len(logCh) > 0 {
+		<-logCh // drain the admin handler's own messages
+	}
+	lgr.Debug("Now reaching the hook")
+	if n := len(logCh); n != 1 {
+		t.Errorf("expected the debug message to reach the hook, got %d messages", n)
+	}
+
+	if code := do("PUT", "/hooks/log_chan", `{"enabled":false}`, &hook); code != http.StatusOK || *hook.Enabled {
+		t.Errorf("PUT /hooks/log_chan = %d %+v", code, hook)
+	}
+	<-logCh
+	lgr.Error("Hook is disabled")
+	if n := len(logCh); n != 0 {
+		t.Errorf("expected the disabled hook not to fire, got %d messages", n)
+	}
+
+	// A change is applied entirely or not at all
+	if code := do("PUT", "/hooks/log_chan", `{"level":"verbose","enabled":true}`, nil); code != http.StatusBadRequest {
+		t.Errorf("expected a bad request for an unknown level, got %d", code)
+	}
+	if code := do("GET", "/hooks/log_chan", "", &hook); code != http.StatusOK || *hook.Enabled || hook.Level != "debug" {
+		t.Errorf("expected the rejected change not to be applied, got %d %+v", code, hook)
+	}
+	if code := do("PUT", "/hooks/log_chan", `{"level":"error","enabled":true}`, &hook); code != http.StatusOK ||
+		!*hook.Enabled || hook.Level != "error" {
+		t.Errorf("PUT /hooks/log_chan = %d %+v", code, hook)
+	}
+
+	if code := do("GET", "/hooks/teams", "", nil); code != http.StatusNotFound {
+		t.Errorf("expected not found for an unconfigured hook, got %d", code)
+	}
+}
+
+func TestAdminHookChangesWhileLogging(t *testing.T) {
+	logCh := make(chan string, 100)
+	lgr := New(LogConfig{LogChanCfg: LogChanCfg{Enabled: true, Ch: logCh, LogLevel: "warn"}})
+	defer lgr.Close()
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+
+	srv := httptest.NewServer(lgr.AdminHandler())
+	defer srv.Close()
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				lgr.Warn("Disk usage high")
+				select {
+				case <-logCh:
+				default:
+				}
+			}
+		}
+	}()
+
+	for i := range 10 {
+		body := fmt.Sprintf(`{"level":"info","enabled":%t}`, i%2 == 0)
+		req, _ := http.NewRequest("PUT", srv.URL+"/hooks/log_chan", strings.NewReader(body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+	}
+	close(stop)
+	wg.Wait()
+}
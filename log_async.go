@@ -1,49 +1,277 @@
 package logger
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rohanthewiz/serr"
+	"github.com/sirupsen/logrus"
+)
+
+// Async queue overflow policies - what LogAsync does when the queue is full
+const (
+	AsyncPolicyBlock       = "block"         // wait for room in the queue (default)
+	AsyncPolicyDropNewest  = "drop-newest"   // discard the message being logged
+	AsyncPolicyDropOldest  = "drop-oldest"   // discard the oldest queued message to make room
+	AsyncPolicySpillToSync = "spill-to-sync" // log the message synchronously in the caller
+)
+
+// AsyncStats are counters for the async queue of a Logger
+type AsyncStats struct {
+	Policy    string
+	Capacity  int    // size of the queue
+	Queued    int    // messages currently waiting in the queue
+	Enqueued  uint64 // messages accepted into the queue
+	Processed uint64 // messages logged by the queue worker
+	Dropped   uint64 // messages discarded by the drop-newest / drop-oldest policies
+	Spilled   uint64 // messages logged synchronously because the queue was full or closed
+}
+
+// asyncEntry is a log message waiting in the async queue
+type asyncEntry struct {
+	lgr    *Logger // the producing Logger, so fields bound via With() are included
+	level  string
+	msg    string
+	args   []any
+	time   time.Time // time of the LogAsync call
+	caller string    // location of the LogAsync call, if enabled
+}
+
+// asyncQueue is a fixed size queue of log messages drained by a single worker goroutine.
+// Producers enqueue directly (no goroutine per message) so memory is bounded by the queue size,
+// and messages from one producer are logged in call order
+type asyncQueue struct {
+	ch     chan asyncEntry
+	policy string
+	caller bool          // capture the location of LogAsync calls
+	done   chan struct{} // closed when the worker has processed everything
+
+	mu     sync.RWMutex // read-locked by producers, write-locked to close the queue
+	closed bool
+
+	enqueued, processed, dropped, spilled atomic.Uint64
+	pending                               atomic.Int64 // messages queued or being processed
+}
+
+func newAsyncQueue(size int, policy string, caller bool) *asyncQueue {
+	return &asyncQueue{
+		ch:     make(chan asyncEntry, size),
+		policy: asyncPolicy(policy),
+		caller: caller,
+		done:   make(chan struct{}),
+	}
+}
+
+// asyncPolicy validates policy, defaulting to AsyncPolicyBlock
+func asyncPolicy(policy string) string {
+	policy = strings.ToLower(policy)
+	switch policy {
+	case AsyncPolicyBlock, AsyncPolicyDropNewest, AsyncPolicyDropOldest, AsyncPolicySpillToSync:
+		return policy
+	default:
+		return AsyncPolicyBlock
+	}
+}
+
+// enqueue adds ent to the queue according to the overflow policy.
+// It returns false if the caller should log ent synchronously instead
+func (q *asyncQueue) enqueue(ent asyncEntry) (queued bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		q.spilled.Add(1)
+		return false
+	}
+
+	q.pending.Add(1) // count before sending so the worker never sees a negative count
+
+	switch q.policy {
+	case AsyncPolicyDropNewest:
+		select {
+		case q.ch <- ent:
+		default:
+			q.pending.Add(-1)
+			q.dropped.Add(1)
+			return true
+		}
+
+	case AsyncPolicyDropOldest:
+		for sent := false; !sent; {
+			select {
+			case q.ch <- ent:
+				sent = true
+			default:
+				select { // make room by discarding the oldest message
+				case <-q.ch:
+					q.pending.Add(-1)
+					q.dropped.Add(1)
+				default:
+				}
+			}
+		}
+
+	case AsyncPolicySpillToSync:
+		select {
+		case q.ch <- ent:
+		default:
+			q.pending.Add(-1)
+			q.spilled.Add(1)
+			return false
+		}
+
+	default: // block
+		q.ch <- ent
+	}
+
+	q.enqueued.Add(1)
+	return true
+}
+
+// close stops the queue from accepting messages and waits for the worker to drain it,
+// or for ctx to be done
+func (q *asyncQueue) close(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.ch)
+	}
+	q.mu.Unlock()
+
+	select {
+	case <-q.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("async queue: %d messages unprocessed: %w", q.pending.Load(), ctx.Err())
+	}
+}
+
+// flush waits for all messages queued so far to be processed, or for ctx to be done
+func (q *asyncQueue) flush(ctx context.Context) error {
+	ticker := time.NewTicker(flushPollInterval)
+	defer ticker.Stop()
+
+	for q.pending.Load() > 0 {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("async queue: %d messages unprocessed: %w", q.pending.Load(), ctx.Err())
+		}
+	}
+	return nil
+}
+
+func (q *asyncQueue) isClosed() bool {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	return q.closed
+}
+
+func (q *asyncQueue) stats() AsyncStats {
+	return AsyncStats{
+		Policy:    q.policy,
+		Capacity:  cap(q.ch),
+		Queued:    len(q.ch),
+		Enqueued:  q.enqueued.Load(),
+		Processed: q.processed.Load(),
+		Dropped:   q.dropped.Load(),
+		Spilled:   q.spilled.Load(),
+	}
+}
 
 // Async is a convenience function for LogAsync
 func Async(level, msg string, args ...string) {
-	LogAsync(level, msg, args...)
+	defaultLogger.logAsync(level, msg, anyArgsFromStrArgs(args))
 }
 
-// LogAsync adds a log message asynchronously to the queue (logsChannel)
+// LogAsync adds a log message asynchronously to the queue of the default Logger
 // level can be one of "debug", "info", "warn", "error"
 // msg is required and should be a simple description of the event we are logging
 // args are (optional) pairs of of attribute, values
+// The entry is timestamped at the time of this call, not when it is processed
 // Example: LogAsync("info", "Downloading a file", "filename", "export.xlsx")
 func LogAsync(level, msg string, args ...string) {
-	if logsChannel == nil {
+	defaultLogger.logAsync(level, msg, anyArgsFromStrArgs(args))
+}
+
+// GetAsyncStats returns the counters of the default Logger's async queue
+func GetAsyncStats() AsyncStats {
+	return defaultLogger.AsyncStats()
+}
+
+// Async is a convenience method for LogAsync
+func (l *Logger) Async(level, msg string, args ...string) {
+	l.logAsync(level, msg, anyArgsFromStrArgs(args))
+}
+
+// LogAsync adds a log message asynchronously to the queue
+// See the package level LogAsync for details
+func (l *Logger) LogAsync(level, msg string, args ...string) {
+	l.logAsync(level, msg, anyArgsFromStrArgs(args))
+}
+
+// logAsync is the common core of the async functions.
+// It must be called directly by the exported functions to keep the caller frame level consistent
+func (l *Logger) logAsync(level, msg string, args []any) {
+	q := l.async.Load()
+	if q == nil {
 		fmt.Println("Logs not setup for Async. Use InitLog() to setup.",
 			"msg:", msg, "args:", args)
 		return
 	}
 
-	argsSlice := [][]byte{[]byte(level), []byte(msg)} // first section of arguments
-	for _, arg := range args {                        // get the rest of the arguments
-		argsSlice = append(argsSlice, []byte(arg))
+	ent := asyncEntry{lgr: l, level: level, msg: msg, args: args, time: time.Now()}
+	if q.caller {
+		ent.caller = serr.FunctionLoc(serr.FrameLevels.FrameLevel3)
 	}
 
-	logsWaitGroup.Add(1) // track the number of log senders
-	go func() {
-		logsChannel <- argsSlice // send to the channel.
-		logsWaitGroup.Done()     // one less log sender
-	}()
+	if !q.enqueue(ent) {
+		ent.log() // spilled to sync
+	}
+}
+
+// log logs the entry with the time (and location) of the original call
+func (ent asyncEntry) log() {
+	args := ent.args
+	if ent.caller != "" { // prepend, so a location given in args still wins
+		args = append([]any{"location", ent.caller}, args...)
+	}
+	ent.lgr.logKeyValsAt(ent.time, ent.level, ent.msg, args)
 }
 
-// Poll the LogsChannel for incoming messages of [][]byte
-func pollForLogs(logsChan <-chan [][]byte, done chan<- bool) {
+// logRecovered logs the entry in the worker, which must survive a panic level entry
+// (logrus panics once it is logged) or a panicking hook
+func (ent asyncEntry) logRecovered() {
 	defer func() {
-		done <- true // signal caller when we are done // Flush any hooks here
-	}()
-	for {
-		select { // select blocks until there is a message on a monitored channel
-		case attrs, ok := <-logsChan:
-			if !ok { // the channel is closed *and* empty, so wrap up
-				return
-			} else {
-				logBytes(string(attrs[0]), string(attrs[1]), attrs[2:]...) // receive the item and call Log()
+		if r := recover(); r != nil {
+			if _, logged := r.(*logrus.Entry); !logged {
+				fmt.Println("Recovered from panic while logging async message:", r)
 			}
 		}
+	}()
+	ent.log()
+}
+
+// AsyncStats returns the counters of this Logger's async queue
+func (l *Logger) AsyncStats() AsyncStats {
+	q := l.async.Load()
+	if q == nil {
+		return AsyncStats{}
+	}
+	return q.stats()
+}
+
+// pollForLogs logs the messages of the queue until it is closed and empty
+func (l *Logger) pollForLogs(q *asyncQueue) {
+	defer close(q.done) // signal the closer when we are done
+
+	for ent := range q.ch { // the loop ends when the channel is closed *and* empty
+		ent.logRecovered()
+		q.processed.Add(1)
+		q.pending.Add(-1)
 	}
 }
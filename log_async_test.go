@@ -0,0 +1,171 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestAsyncQueuePolicies(t *testing.T) {
+	tests := []struct {
+		policy       string
+		expectedMsgs []string
+		expected     AsyncStats
+	}{
+		{AsyncPolicyDropNewest, []string{"msg1", "msg2"}, AsyncStats{Enqueued: 2, Dropped: 1}},
+		{AsyncPolicyDropOldest, []string{"msg2", "msg3"}, AsyncStats{Enqueued: 3, Dropped: 1}},
+		{AsyncPolicySpillToSync, []string{"msg1", "msg2"}, AsyncStats{Enqueued: 2, Spilled: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			q := newAsyncQueue(2, tt.policy, false) // no worker, so the queue fills up
+
+			queued := 0
+			for _, msg := range []string{"msg1", "msg2", "msg3"} {
+				if q.enqueue(asyncEntry{level: "info", msg: msg}) {
+					queued++
+				}
+			}
+
+			stats := q.stats()
+			if stats.Enqueued != tt.expected.Enqueued || stats.Dropped != tt.expected.Dropped ||
+				stats.Spilled != tt.expected.Spilled || stats.Queued != 2 || stats.Capacity != 2 {
+				t.Errorf("unexpected stats %+v", stats)
+			}
+			if tt.policy == AsyncPolicySpillToSync && queued != 2 {
+				t.Errorf("expected the third message to be handed back for sync logging")
+			}
+
+			close(q.ch)
+			var msgs []string
+			for ent := range q.ch {
+				msgs = append(msgs, ent.msg)
+			}
+			if strings.Join(msgs, ",") != strings.Join(tt.expectedMsgs, ",") {
+				t.Errorf("queued messages = %v, expected %v", msgs, tt.expectedMsgs)
+			}
+		})
+	}
+}
+
+func TestAsyncStatsAndCloseDrains(t *testing.T) {
+	lgr := New(LogConfig{Formatter: "json", LogLevel: "debug", LogChanSize: 4, AsyncPolicy: AsyncPolicyBlock})
+
+	var out bytes.Buffer
+	lgr.Logrus().SetOutput(&out)
+
+	for range 100 {
+		lgr.Async("info", "Async burst message")
+	}
+	lgr.Close()
+	lgr.Async("info", "Logged synchronously after close")
+
+	stats := lgr.AsyncStats()
+	if stats.Enqueued != 100 || stats.Processed != 100 || stats.Dropped != 0 || stats.Spilled != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if n := strings.Count(out.String(), "Async burst message"); n != 100 {
+		t.Errorf("expected 100 async messages, got %d", n)
+	}
+	if !strings.Contains(out.String(), "Logged synchronously after close") {
+		t.Error("expected message after close to be logged synchronously")
+	}
+}
+
+// slowHook delays processing of entries with the message "slow"
+type slowHook struct{ delay time.Duration }
+
+func (h slowHook) Levels() []logrus.Level { return logrus.AllLevels }
+func (h slowHook) Fire(entry *logrus.Entry) error {
+	if entry.Message == "slow" {
+		time.Sleep(h.delay)
+	}
+	return nil
+}
+
+func TestAsyncOrderAndCallTime(t *testing.T) {
+	lgr := New(LogConfig{LogLevel: "debug", AsyncCaller: true})
+
+	var out bytes.Buffer
+	lgr.Logrus().SetOutput(&out)
+	lgr.Logrus().SetFormatter(&logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano})
+	lgr.Logrus().AddHook(slowHook{delay: 100 * time.Millisecond})
+
+	lgr.Async("info", "slow")
+	callTime := time.Now()
+	lgr.Async("info", "after slow")
+
+	var wg sync.WaitGroup
+	for p := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 50 {
+				lgr.Async("info", "ordered", "producer", strconv.Itoa(p), "seq", strconv.Itoa(i))
+			}
+		}()
+	}
+	wg.Wait()
+	lgr.Close()
+
+	lastSeq := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("unable to unmarshal %q: %v", line, err)
+		}
+
+		switch entry["msg"] {
+		case "after slow":
+			ts, err := time.Parse(time.RFC3339Nano, entry["time"].(string))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ts.Sub(callTime) > 50*time.Millisecond {
+				t.Errorf("expected the time of the call (%v), got the processing time %v", callTime, ts)
+			}
+			if loc, _ := entry["location"].(string); !strings.Contains(loc, "log_async_test.go:") {
+				t.Errorf("expected the location of the Async call, got %q", loc)
+			}
+
+		case "ordered":
+			producer := entry["producer"].(string)
+			seq, _ := strconv.Atoi(entry["seq"].(string))
+			if last, ok := lastSeq[producer]; ok && seq != last+1 {
+				t.Errorf("producer %s: seq %d logged after %d", producer, seq, last)
+			}
+			lastSeq[producer] = seq
+		}
+	}
+
+	if len(lastSeq) != 4 {
+		t.Errorf("expected messages from 4 producers, got %d", len(lastSeq))
+	}
+}
+
+func TestAsyncPanicLevelDoesNotKillWorker(t *testing.T) {
+	lgr := New(LogConfig{Formatter: "json", LogLevel: "debug"})
+
+	var out bytes.Buffer
+	lgr.Logrus().SetOutput(&out)
+
+	lgr.Async("panic", "Invariant violated")
+	lgr.Async("info", "Still logging")
+	lgr.Close()
+
+	for _, exp := range []string{`"level":"panic","msg":"Invariant violated"`, `"msg":"Still logging"`} {
+		if !strings.Contains(out.String(), exp) {
+			t.Errorf("expected %s in output: %s", exp, out.String())
+		}
+	}
+	if stats := lgr.AsyncStats(); stats.Processed != 2 {
+		t.Errorf("expected both messages to be processed, got %+v", stats)
+	}
+}
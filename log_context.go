@@ -0,0 +1,110 @@
+package logger
+
+import "context"
+
+type ctxFieldsKey struct{}
+
+// WithFields returns a copy of ctx carrying the key value pairs keyVals.
+// Fields accumulate over nested calls and are merged into every entry logged with the *Ctx functions.
+// Fields given at the log call take precedence over context fields of the same key
+// Example:
+//
+//	ctx = logger.WithFields(ctx, "request_id", reqID, "tenant", tenant)
+//	logger.InfoCtx(ctx, "Fetching invoices", "count", 12)
+//	// => {"count":12,"level":"info","msg":"Fetching invoices","request_id":"8f2c...","tenant":"acme","time":"..."}
+func WithFields(ctx context.Context, keyVals ...any) context.Context {
+	if len(keyVals) == 0 {
+		return ctx
+	}
+
+	existing := FieldsFromContext(ctx)
+	flds := make([]any, 0, len(existing)+len(keyVals)+1)
+	flds = append(flds, existing...)
+	flds = append(flds, keyVals...)
+	if len(keyVals)%2 != 0 {
+		flds = append(flds, "") // a dangling key gets an empty value
+	}
+
+	return context.WithValue(ctx, ctxFieldsKey{}, flds)
+}
+
+// FieldsFromContext returns the key value pairs attached to ctx by WithFields
+func FieldsFromContext(ctx context.Context) []any {
+	if ctx == nil {
+		return nil
+	}
+	flds, _ := ctx.Value(ctxFieldsKey{}).([]any)
+	return flds
+}
+
+// withCtxFields prepends the context fields to args, so args override on duplicate keys
+func withCtxFields(ctx context.Context, args []any) []any {
+	ctxFlds := FieldsFromContext(ctx)
+	if len(ctxFlds) == 0 {
+		return args
+	}
+	out := make([]any, 0, len(ctxFlds)+len(args))
+	out = append(out, ctxFlds...)
+	return append(out, args...)
+}
+
+// DebugCtx logs at debug level including the fields carried in ctx
+func DebugCtx(ctx context.Context, msg string, args ...any) {
+	defaultLogger.logKeyVals(StrLevelDebug, msg, withCtxFields(ctx, args))
+}
+
+// InfoCtx logs at info level including the fields carried in ctx
+func InfoCtx(ctx context.Context, msg string, args ...any) {
+	defaultLogger.logKeyVals(StrLevelInfo, msg, withCtxFields(ctx, args))
+}
+
+// WarnCtx logs at warn level including the fields carried in ctx
+func WarnCtx(ctx context.Context, msg string, args ...any) {
+	defaultLogger.logKeyVals(StrLevelWarn, msg, withCtxFields(ctx, args))
+}
+
+// ErrorCtx logs at error level including the fields carried in ctx
+// It is better to use ErrCtx(ctx, err, ...) if you are logging an existing error
+func ErrorCtx(ctx context.Context, msg string, args ...any) {
+	defaultLogger.logKeyVals(StrLevelError, msg, withCtxFields(ctx, args))
+}
+
+// ErrCtx is LogErr including the fields carried in ctx
+func ErrCtx(ctx context.Context, err error, keyValPairs ...any) {
+	defaultLogger.logErrCore(FieldsFromContext(ctx), err, keyValPairs...)
+}
+
+// AsyncCtx is LogAsync including the fields carried in ctx
+func AsyncCtx(ctx context.Context, level, msg string, args ...string) {
+	defaultLogger.logAsync(level, msg, withCtxFields(ctx, anyArgsFromStrArgs(args)))
+}
+
+// DebugCtx logs at debug level including the fields carried in ctx
+func (l *Logger) DebugCtx(ctx context.Context, msg string, args ...any) {
+	l.logKeyVals(StrLevelDebug, msg, withCtxFields(ctx, args))
+}
+
+// InfoCtx logs at info level including the fields carried in ctx
+func (l *Logger) InfoCtx(ctx context.Context, msg string, args ...any) {
+	l.logKeyVals(StrLevelInfo, msg, withCtxFields(ctx, args))
+}
+
+// WarnCtx logs at warn level including the fields carried in ctx
+func (l *Logger) WarnCtx(ctx context.Context, msg string, args ...any) {
+	l.logKeyVals(StrLevelWarn, msg, withCtxFields(ctx, args))
+}
+
+// ErrorCtx logs at error level including the fields carried in ctx
+func (l *Logger) ErrorCtx(ctx context.Context, msg string, args ...any) {
+	l.logKeyVals(StrLevelError, msg, withCtxFields(ctx, args))
+}
+
+// ErrCtx is LogErr including the fields carried in ctx
+func (l *Logger) ErrCtx(ctx context.Context, err error, keyValPairs ...any) {
+	l.logErrCore(FieldsFromContext(ctx), err, keyValPairs...)
+}
+
+// AsyncCtx is LogAsync including the fields carried in ctx
+func (l *Logger) AsyncCtx(ctx context.Context, level, msg string, args ...string) {
+	l.logAsync(level, msg, withCtxFields(ctx, anyArgsFromStrArgs(args)))
+}
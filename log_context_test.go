@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestContextFields(t *testing.T) {
+	lgr := New(LogConfig{Formatter: "json", LogLevel: "debug"})
+
+	var out bytes.Buffer
+	lgr.Logrus().SetOutput(&out)
+
+	ctx := WithFields(context.Background(), "request_id", "req-123", "tenant", "acme")
+	ctx = WithFields(ctx, "user", "u-42")
+
+	lgr.InfoCtx(ctx, "Fetching invoices", "count", 12, "tenant", "override")
+	lgr.ErrCtx(ctx, errors.New("invoice not found"), "invoice_id", "INV-1")
+	lgr.ErrCtx(ctx, errors.New("tenant suspended"), "tenant", "override")
+	lgr.AsyncCtx(ctx, "warn", "Async with context", "key1", "value1")
+	lgr.Close()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) < 4 {
+		t.Fatalf("expected at least 4 log lines, got: %s", out.String())
+	}
+
+	checks := []struct {
+		line       string
+		expected   []string
+		unexpected []string
+	}{
+		{lines[0], []string{`"request_id":"req-123"`, `"user":"u-42"`, `"tenant":"override"`, `"count":12`}, nil},
+		{lines[1], []string{`"request_id":"req-123"`, `"tenant":"acme"`, `"invoice_id":"INV-1"`, `log_context_test.go:`}, nil},
+		{lines[2], []string{`"request_id":"req-123"`, `"tenant":"override"`}, []string{`acme`}},
+		{lines[3], []string{`"request_id":"req-123"`, `"user":"u-42"`, `"key1":"value1"`, `"msg":"Async with context"`}, nil},
+	}
+	for i, chk := range checks {
+		for _, exp := range chk.expected {
+			if !strings.Contains(chk.line, exp) {
+				t.Errorf("line %d: expected %s in %s", i, exp, chk.line)
+			}
+		}
+		for _, unexp := range chk.unexpected {
+			if strings.Contains(chk.line, unexp) {
+				t.Errorf("line %d: did not expect %s in %s", i, unexp, chk.line)
+			}
+		}
+	}
+
+	if FieldsFromContext(context.Background()) != nil {
+		t.Error("expected no fields in a bare context")
+	}
+}
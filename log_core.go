@@ -3,12 +3,14 @@ package logger
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
-// Log prepares fields and messages and logs to logrus
-// Level can be one of "debug", "info", "warn", "error", "fatal"
+// Log prepares fields and messages and logs to logrus via the default Logger
+// Level can be one of "trace", "debug", "info", "warn", "error", "fatal", "panic"
+// An unknown level is logged at info, with a warning
 // `args` should be a list of argument pairs
 // Example:
 //
@@ -17,50 +19,81 @@ import (
 //	logger.Log("Warn", "Weird things are happening", "thing1", "value1", "thing2", "value2")
 //		- or logger.Warn("Weird things are happening", "thing1", "value1", "thing2", "value2")
 func Log(level, msg string, args ...string) {
+	defaultLogger.Log(level, msg, args...)
+}
+
+// Log prepares fields and messages and logs to this Logger's logrus instance
+// See the package level Log for details
+func (l *Logger) Log(level, msg string, args ...string) {
+	l.logKeyVals(level, msg, anyArgsFromStrArgs(args))
+}
+
+// logKeyVals is the typed core of Log and the convenience wrappers.
+// Values keep their type (numbers, booleans, maps, ...) so the JSON formatter emits them natively
+func (l *Logger) logKeyVals(level, msg string, args []any) {
+	l.logKeyValsAt(time.Time{}, level, msg, args)
+}
+
+// logKeyValsAt is logKeyVals for an entry created at time t (e.g. when an async message was logged).
+// A zero t means now
+func (l *Logger) logKeyValsAt(t time.Time, level, msg string, args []any) {
 	flds := logrus.Fields{}
 
 	// Gather the other keys and values
 	key := ""
 	for i, arg := range args {
 		if i%2 == 0 { // arg is a key
-			key = arg
+			key = fmt.Sprintf("%v", arg)
 		} else {
-			flds[key] = arg
+			flds[key] = fieldValue(arg)
 		}
 	}
 
 	// Fixup / Validate
 	if len(args)%2 != 0 {
-		logrus.Warn(fmt.Sprintf("Even number of args required for Log() function (nbr of args: %d)", len(args)),
+		l.lr.Warn(fmt.Sprintf("Even number of args required for Log() function (nbr of args: %d)", len(args)),
 			" msg ", msg, " args ", fmt.Sprintf("%#v", args))
 	}
 
-	if logPrefix != "" {
-		msg = logPrefix + " " + msg
+	// "fatal" calls os.Exit() after logging, "panic" panics after logging
+	lvl, ok := logrusLevels[strings.ToLower(level)]
+	if !ok { // don't lose the message
+		l.lr.Warn(fmt.Sprintf("Unknown level %q passed to Log() function, logging at info", level),
+			" msg ", msg)
+		lvl = logrus.InfoLevel
 	}
 
-	// Call the logger
-	lg := logrus.WithFields(flds)
+	l.logEntry(lvl, msg, flds, t)
+}
 
-	switch strings.ToLower(level) {
-	case "debug":
-		lg.Debug(msg)
-	case "info":
-		lg.Info(msg)
-	case "warn":
-		lg.Warn(msg)
-	case "error":
-		lg.Error(msg) // Log error, but don't quit
-	case "fatal":
-		lg.Fatal(msg) // Calls os.Exit() after logging
+// logEntry is the common landing point for entries of all front ends (Log, slog, etc.)
+// It applies the prefix and bound fields and hands the entry to logrus.
+// A zero t means the entry is stamped by logrus at the time of logging
+func (l *Logger) logEntry(level logrus.Level, msg string, flds logrus.Fields, t time.Time) {
+	if !l.levelEnabled(level) {
+		return
 	}
-}
 
-// Landing point for Async log messages
-func logBytes(level string, msg string, args ...[]byte) {
-	var strArgs []string
-	for _, arg := range args {
-		strArgs = append(strArgs, string(arg))
+	if prefix := l.Prefix(); prefix != "" {
+		msg = prefix + " " + msg
+	}
+
+	// Add bound fields (see With) unless overridden at the call
+	for k, v := range l.fields {
+		if _, ok := flds[k]; !ok {
+			flds[k] = v
+		}
+	}
+
+	// Call the logger
+	lg := l.withName(flds)
+	if !t.IsZero() {
+		lg = lg.WithTime(t)
+	}
+
+	if level == logrus.FatalLevel {
+		lg.Fatal(msg) // Calls os.Exit() after logging
+		return
 	}
-	Log(level, msg, strArgs...)
+	lg.Log(level, msg)
 }
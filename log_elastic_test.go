@@ -0,0 +1,150 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rohanthewiz/logger/hooks/elastic_log"
+	"github.com/sirupsen/logrus"
+)
+
+// bulkStandIn indexes _bulk requests, rejecting documents by message:
+// "Throttled" with 429 on its first attempt, "Bad mapping" with 400 always
+type bulkStandIn struct {
+	mu        sync.Mutex
+	requests  int
+	indexed   map[string][]map[string]any // by index
+	throttled bool
+	auth      string
+}
+
+func (bs *bulkStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	if r.URL.Path != "/_bulk" || r.Header.Get("Content-Type") != "application/x-ndjson" {
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+		return
+	}
+	bs.requests++
+	bs.auth = r.Header.Get("Authorization")
+
+	var items []string
+	errors := false
+	sc := bufio.NewScanner(r.Body)
+	for sc.Scan() {
+		var action map[string]map[string]string
+		if err := json.Unmarshal(sc.Bytes(), &action); err != nil || !sc.Scan() {
+			http.Error(w, "malformed bulk body", http.StatusBadRequest)
+			return
+		}
+		var doc map[string]any
+		_ = json.Unmarshal(sc.Bytes(), &doc)
+
+		status := http.StatusCreated
+		switch doc["message"] {
+		case "Throttled":
+			if !bs.throttled {
+				bs.throttled, status = true, http.StatusTooManyRequests
+			}
+		case "Bad mapping":
+			status = http.StatusBadRequest
+		}
+
+		if status == http.StatusCreated {
+			index := action["index"]["_index"]
+			bs.indexed[index] = append(bs.indexed[index], doc)
+			items = append(items, fmt.Sprintf(`{"index":{"status":%d}}`, status))
+		} else {
+			errors = true
+			items = append(items, fmt.Sprintf(`{"index":{"status":%d,"error":{"type":"rejected","reason":"test"}}}`, status))
+		}
+	}
+
+	_, _ = fmt.Fprintf(w, `{"took":1,"errors":%t,"items":[%s]}`, errors, strings.Join(items, ","))
+}
+
+func TestElasticBulk(t *testing.T) {
+	es := &bulkStandIn{indexed: map[string][]map[string]any{}}
+	srv := httptest.NewServer(es)
+	defer srv.Close()
+
+	hook := elastic_log.NewElasticHook(srv.URL, "app-logs-{2006.01.02}", logrus.AllLevels)
+	hook.APIKey = "aWQ6a2V5"
+	hook.Backoff.Min = 5 * time.Millisecond
+
+	lgr := New(LogConfig{LogLevel: "debug"})
+	defer lgr.Close()
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+	lgr.Logrus().AddHook(hook)
+
+	lgr.Info("Order shipped", "order_id", "A-1001", "message", "from a field")
+	lgr.Warn("Throttled")
+	lgr.Error("Bad mapping")
+
+	if err := hook.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	if es.requests != 2 {
+		t.Errorf("expected a bulk request and a retry, got %d requests", es.requests)
+	}
+	if es.auth != "ApiKey aWQ6a2V5" {
+		t.Errorf("unexpected authorization %q", es.auth)
+	}
+
+	index := "app-logs-" + time.Now().UTC().Format("2006.01.02")
+	docs := es.indexed[index]
+	if len(docs) != 2 {
+		t.Fatalf("expected 2 documents in %s, got %v", index, es.indexed)
+	}
+	first := docs[0]
+	if first["message"] != "Order shipped" || first["fields.message"] != "from a field" ||
+		first["order_id"] != "A-1001" || first["level"] != "info" || first["@timestamp"] == nil {
+		t.Errorf("unexpected document %v", first)
+	}
+	if docs[1]["message"] != "Throttled" {
+		t.Errorf("expected the throttled document to be retried, got %v", docs[1])
+	}
+}
+
+func TestElasticFromConfig(t *testing.T) {
+	es := &bulkStandIn{indexed: map[string][]map[string]any{}}
+	srv := httptest.NewServer(es)
+	defer srv.Close()
+
+	lgr := New(LogConfig{
+		LogLevel:   "debug",
+		ElasticCfg: ElasticCfg{Enabled: true, URL: srv.URL, Username: "shipper", Password: "secret", LogLevel: "warn"},
+	})
+	defer lgr.Close()
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+
+	lgr.Info("Below the elastic level")
+	lgr.Warn("Disk usage high")
+	if err := lgr.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	docs := es.indexed["logs-"+time.Now().UTC().Format("2006.01.02")]
+	if len(docs) != 1 || docs[0]["message"] != "Disk usage high" {
+		t.Errorf("expected only the warning to be indexed, got %v", es.indexed)
+	}
+	if !strings.HasPrefix(es.auth, "Basic ") {
+		t.Errorf("expected basic auth, got %q", es.auth)
+	}
+}
@@ -23,24 +23,40 @@ const (
 //
 // see the tests for more examples
 func LogErr(err error, keyValPairs ...any) {
-	logErrCore(err, keyValPairs...)
+	defaultLogger.logErrCore(nil, err, keyValPairs...)
 }
 
 // Err is a convenience wrapper for LogErr
 // We have to duplicate the function body or use a common core so as to keep error framelevels consistent
 func Err(err error, keyValPairs ...any) {
-	logErrCore(err, keyValPairs...)
+	defaultLogger.logErrCore(nil, err, keyValPairs...)
+}
+
+// LogErr logs a structured error (SErr) to this Logger
+// See the package level LogErr for details
+func (l *Logger) LogErr(err error, keyValPairs ...any) {
+	l.logErrCore(nil, err, keyValPairs...)
+}
+
+// Err is a convenience wrapper for LogErr
+func (l *Logger) Err(err error, keyValPairs ...any) {
+	l.logErrCore(nil, err, keyValPairs...)
 }
 
 // logErrCore is the common core for logging errors.
 // This exists so as to keep framelevels consistent among calling functions
-func logErrCore(err error, keyValPairs ...any) {
+// ctxFields are key value pairs carried in a context (see WithFields)
+func (l *Logger) logErrCore(ctxFields []any, err error, keyValPairs ...any) {
 	if err == nil {
-		Log(LogLevel.Info, "In LogErr Not logging a nil err", "called from",
+		l.Log(LogLevel.Info, "In LogErr Not logging a nil err", "called from",
 			serr.FunctionLoc(serr.FrameLevels.FrameLevel3))
 		return
 	}
 
+	if !l.levelEnabled(logrus.ErrorLevel) {
+		return
+	}
+
 	flds := logrus.Fields{}
 
 	ser, ok := err.(serr.SErr)
@@ -51,6 +67,15 @@ func logErrCore(err error, keyValPairs ...any) {
 	// Add current location context so we don't have to wrap errors at the point of logging
 	ser.AppendCallerContext(serr.FrameLevels.FrameLevel4)
 
+	// Add bound and context fields separately so an odd number of keyValPairs is still fixed up as usual
+	if bound := l.boundKeyVals(); len(bound) > 0 {
+		ser.AppendAttributes(withoutKeys(bound, ctxFields, keyValPairs)...)
+	}
+	if len(ctxFields) > 0 {
+		// serr would merge values of a key given again at the call, which takes precedence
+		ser.AppendAttributes(withoutKeys(ctxFields, keyValPairs)...)
+	}
+
 	// Add any additional attributes
 	ser.AppendAttributes(keyValPairs...)
 
@@ -71,10 +96,14 @@ func logErrCore(err error, keyValPairs ...any) {
 			case strings.ToLower(serr.UserMsgSeverityKey):
 				continue // that one is for UI only
 			case prefixKey:
-				logPrefix = strVal
+				l.setPrefix(strVal)
 				continue
 			default:
-				flds[key] = strVal
+				if len(anyArr) == 1 { // a single value keeps its type
+					flds[key] = fieldValue(anyArr[0])
+				} else {
+					flds[key] = strVal
+				}
 			}
 		}
 
@@ -83,5 +112,27 @@ func logErrCore(err error, keyValPairs ...any) {
 		}
 	}
 
-	logrus.WithFields(flds).Error(logPrefix + err.Error())
+	l.withName(flds).Error(l.Prefix() + err.Error())
+}
+
+// withoutKeys returns the pairs of keyVals whose keys are not among the keys of overriding
+func withoutKeys(keyVals []any, overriding ...[]any) []any {
+	overridden := map[string]bool{}
+	for _, kvs := range overriding {
+		for i := 0; i < len(kvs); i += 2 {
+			overridden[fmt.Sprintf("%v", kvs[i])] = true
+		}
+	}
+	if len(overridden) == 0 {
+		return keyVals
+	}
+
+	out := make([]any, 0, len(keyVals))
+	for i := 0; i < len(keyVals); i += 2 {
+		if overridden[fmt.Sprintf("%v", keyVals[i])] {
+			continue
+		}
+		out = append(out, keyVals[i:min(i+2, len(keyVals))]...)
+	}
+	return out
 }
@@ -0,0 +1,182 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/rohanthewiz/logger/hooks/file_log"
+)
+
+func TestFileLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	lgr := New(LogConfig{
+		Formatter:  "text",
+		LogLevel:   "debug",
+		FileLogCfg: FileLogCfg{Enabled: true, Path: path, LogLevel: "info", Formatter: "json"},
+	})
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+
+	lgr.Debug("Below the file level")
+	lgr.Info("Order shipped", "order_id", "A-1001")
+	lgr.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+
+	if strings.Contains(out, "Below the file level") {
+		t.Errorf("expected debug entries to be filtered out of the file, got %s", out)
+	}
+	if !strings.Contains(out, `"msg":"Order shipped"`) || !strings.Contains(out, `"order_id":"A-1001"`) {
+		t.Errorf("expected a JSON entry in the file, got %s", out)
+	}
+
+	// Close closed the file hook, Reconfigure builds it anew
+	fileCfg := LogConfig{Formatter: "text", LogLevel: "debug", FileLogCfg: FileLogCfg{Enabled: true, Path: path}}
+	if changes := lgr.Reconfigure(fileCfg); !strings.Contains(strings.Join(changes, ","), "hook added: file") {
+		t.Errorf("expected the file hook to be added again, got %v", changes)
+	}
+
+	// Removing the file hook closes the file
+	if changes := lgr.Reconfigure(LogConfig{Formatter: "text", LogLevel: "debug"}); !strings.Contains(strings.Join(changes, ","), "hook removed: file") {
+		t.Errorf("expected the file hook to be removed, got %v", changes)
+	}
+	lgr.Close()
+}
+
+func TestRotatingFileRotatesCompressesAndPrunes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	rf, err := file_log.NewRotatingFile(file_log.RotatingFileCfg{Path: path, Compress: true, MaxBackups: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		if _, err := rf.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+		if err := rf.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := rf.Write([]byte("current\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := rf.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err := filepath.Glob(filepath.Join(dir, "app-*.log.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("expected 2 compressed backups, got %v", backups)
+	}
+	if plain, _ := filepath.Glob(filepath.Join(dir, "app-*.log")); len(plain) != 0 {
+		t.Errorf("expected rotated files to be compressed, found %v", plain)
+	}
+
+	// Backup names sort in rotation order, so the newest is last
+	if content := gunzip(t, backups[1]); content != "fourth\n" {
+		t.Errorf("expected the newest backup to hold %q, got %q", "fourth\n", content)
+	}
+	if content := gunzip(t, backups[0]); content != "third\n" {
+		t.Errorf("expected the oldest remaining backup to hold %q, got %q", "third\n", content)
+	}
+
+	if data, _ := os.ReadFile(path); string(data) != "current\n" {
+		t.Errorf("expected the active file to hold %q, got %q", "current\n", data)
+	}
+}
+
+func TestRotatingFileReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+
+	rf, err := file_log.NewRotatingFile(file_log.RotatingFileCfg{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rf.Close()
+
+	_, _ = rf.Write([]byte("before logrotate\n"))
+
+	// An external logrotate moves the file away, then signals us to reopen it
+	moved := filepath.Join(dir, "app.log.1")
+	if err := os.Rename(path, moved); err != nil {
+		t.Fatal(err)
+	}
+	if err := rf.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = rf.Write([]byte("after logrotate\n"))
+
+	if data, _ := os.ReadFile(moved); string(data) != "before logrotate\n" {
+		t.Errorf("expected the moved file to keep its entries, got %q", data)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "after logrotate\n" {
+		t.Errorf("expected new entries in a new file, got %q", data)
+	}
+}
+
+func gunzip(t *testing.T, path string) string {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRotatingFileCloseTwice(t *testing.T) {
+	rf, err := file_log.NewRotatingFile(file_log.RotatingFileCfg{Path: filepath.Join(t.TempDir(), "app.log"), ReopenOnHUP: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rf.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := rf.Close(); err != nil {
+		t.Errorf("expected a second Close to be a no-op, got %v", err)
+	}
+}
+
+func TestFileLogInvalidPath(t *testing.T) {
+	notADir := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(notADir, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	lgr := New(LogConfig{LogLevel: "debug"})
+	defer lgr.Close()
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+
+	changes := lgr.Reconfigure(LogConfig{LogLevel: "debug", FileLogCfg: FileLogCfg{Enabled: true, Path: filepath.Join(notADir, "app.log")}})
+	if !strings.Contains(strings.Join(changes, ","), "hook disabled: file") {
+		t.Errorf("expected the file hook to be disabled, got %v", changes)
+	}
+	if _, ok := findManagedHook(lgr.hooks, "file"); ok {
+		t.Error("expected no file hook for a path which can't be opened")
+	}
+}
@@ -0,0 +1,262 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/rohanthewiz/logger/hooks/fluent_log"
+	"github.com/sirupsen/logrus"
+)
+
+func TestFluentForwardWithAck(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	type message struct {
+		tag    string
+		events []any
+		option map[string]any
+	}
+	received := make(chan message, 10)
+
+	// A forward input which drops the first connection without acknowledging
+	go func() {
+		for conns := 0; ; conns++ {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			rd := bufio.NewReader(conn)
+			for {
+				msg, err := decodeMsgpack(rd)
+				if err != nil {
+					break
+				}
+				arr := msg.([]any)
+				option := arr[2].(map[string]any)
+				if conns == 0 {
+					break
+				}
+
+				var events []any
+				entries := bytes.NewReader(arr[1].([]byte))
+				for entries.Len() > 0 {
+					ev, err := decodeMsgpack(entries)
+					if err != nil {
+						t.Error(err)
+						break
+					}
+					events = append(events, ev)
+				}
+				received <- message{tag: arr[0].(string), events: events, option: option}
+
+				ack := append([]byte{0x81, 0xa3}, "ack"...)
+				chunk := option["chunk"].(string)
+				ack = append(append(ack, 0xa0|byte(len(chunk))), chunk...)
+				_, _ = conn.Write(ack)
+			}
+			_ = conn.Close()
+		}
+	}()
+
+	lgr := New(LogConfig{
+		LogLevel:  "debug",
+		FluentCfg: FluentCfg{Enabled: true, Address: ln.Addr().String(), Tag: "billing.{level}", RequireAck: true},
+	})
+	defer lgr.Close()
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+
+	start := time.Now()
+	lgr.Info("Order shipped", "order_id", "A-1001", "amount", 99.5, "items", 3)
+	lgr.Info("Invoice sent", "order_id", "A-1001", "message", "reminder", "level", "final")
+	lgr.Error("Payment declined", "order_id", "A-1002")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := lgr.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs := map[string]message{}
+	for range 2 {
+		select {
+		case m := <-received:
+			msgs[m.tag] = m
+		case <-ctx.Done():
+			t.Fatal("expected a message per tag")
+		}
+	}
+
+	info := msgs["billing.info"]
+	if len(info.events) != 2 || info.option["size"] != int64(2) {
+		t.Fatalf("expected 2 info entries in one PackedForward message, got %+v", info)
+	}
+	first := info.events[0].([]any)
+	if ts := first[0].(time.Time); ts.Before(start.Truncate(time.Second)) || time.Since(ts) > time.Minute {
+		t.Errorf("unexpected event time %v", ts)
+	}
+	record := first[1].(map[string]any)
+	if record["message"] != "Order shipped" || record["level"] != "info" || record["order_id"] != "A-1001" ||
+		record["amount"] != 99.5 || record["items"] != int64(3) {
+		t.Errorf("unexpected record %v", record)
+	}
+
+	if second := info.events[1].([]any)[1].(map[string]any); second["message"] != "Invoice sent" || second["level"] != "info" ||
+		second["fields.message"] != "reminder" || second["fields.level"] != "final" {
+		t.Errorf("expected clashing fields kept under fields., got %v", second)
+	}
+
+	if errMsg := msgs["billing.error"]; len(errMsg.events) != 1 {
+		t.Errorf("expected the error in its own tag, got %+v", errMsg)
+	}
+}
+
+// decodeMsgpack decodes the msgpack types the forward protocol uses.
+// Integers decode as int64, EventTime as time.Time
+func decodeMsgpack(r io.Reader) (any, error) {
+	read := func(n int) ([]byte, error) {
+		b := make([]byte, n)
+		_, err := io.ReadFull(r, b)
+		return b, err
+	}
+	uint := func(n int) (uint64, error) {
+		b, err := read(n)
+		if err != nil {
+			return 0, err
+		}
+		var v uint64
+		for _, c := range b {
+			v = v<<8 | uint64(c)
+		}
+		return v, nil
+	}
+	array := func(n int) (any, error) {
+		arr := make([]any, n)
+		for i := range arr {
+			v, err := decodeMsgpack(r)
+			if err != nil {
+				return nil, err
+			}
+			arr[i] = v
+		}
+		return arr, nil
+	}
+	mapOf := func(n int) (any, error) {
+		m := make(map[string]any, n)
+		for range n {
+			k, err := decodeMsgpack(r)
+			if err != nil {
+				return nil, err
+			}
+			v, err := decodeMsgpack(r)
+			if err != nil {
+				return nil, err
+			}
+			m[k.(string)] = v
+		}
+		return m, nil
+	}
+	sized := func(lenBytes int, fn func(int) (any, error)) (any, error) {
+		n, err := uint(lenBytes)
+		if err != nil {
+			return nil, err
+		}
+		return fn(int(n))
+	}
+	str := func(n int) (any, error) { b, err := read(n); return string(b), err }
+	bin := func(n int) (any, error) { return read(n) }
+
+	b, err := read(1)
+	if err != nil {
+		return nil, err
+	}
+	c := b[0]
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c&0xf0 == 0x80:
+		return mapOf(int(c & 0x0f))
+	case c&0xf0 == 0x90:
+		return array(int(c & 0x0f))
+	case c&0xe0 == 0xa0:
+		return str(int(c & 0x1f))
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2, 0xc3:
+		return c == 0xc3, nil
+	case 0xc4, 0xc5, 0xc6:
+		return sized(1<<(c-0xc4), bin)
+	case 0xcb:
+		v, err := uint(8)
+		return math.Float64frombits(v), err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		v, err := uint(1 << (c - 0xcc))
+		return int64(v), err
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		n := 1 << (c - 0xd0)
+		v, err := uint(n)
+		return int64(v<<(64-8*n)) >> (64 - 8*n), err // sign extend
+	case 0xd7:
+		ext, err := read(9)
+		if err != nil || ext[0] != 0 {
+			return nil, fmt.Errorf("unexpected ext %v", ext)
+		}
+		return time.Unix(int64(binary.BigEndian.Uint32(ext[1:5])), int64(binary.BigEndian.Uint32(ext[5:]))), nil
+	case 0xd9, 0xda, 0xdb:
+		return sized(1<<(c-0xd9), str)
+	case 0xdc, 0xdd:
+		return sized(2<<(c-0xdc), array)
+	case 0xde, 0xdf:
+		return sized(2<<(c-0xde), mapOf)
+	}
+	return nil, fmt.Errorf("unsupported msgpack type 0x%x", c)
+}
+
+func TestFluentCloseWhileWaitingForAck(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	// A forward input which never acknowledges
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		_, _ = io.Copy(io.Discard, conn)
+	}()
+
+	hook := fluent_log.NewFluentHook("tcp", ln.Addr().String(), "billing", logrus.AllLevels)
+	hook.RequireAck = true
+	hook.Timeout = 5200 * time.Millisecond // outlasts the close timeout
+	hook.Backoff.MaxRetries = 0
+
+	lr := logrus.New()
+	lr.SetOutput(io.Discard)
+	lr.AddHook(hook)
+	lr.Error("Payment declined")
+
+	if err := hook.Close(); err == nil {
+		t.Fatal("expected Close to time out while the ack is awaited")
+	}
+	time.Sleep(500 * time.Millisecond) // the sender gives up on the ack and closes the connection
+}
@@ -0,0 +1,163 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// flushPollInterval is how often flushing checks for pending deliveries
+const flushPollInterval = 10 * time.Millisecond
+
+// HookFlusher is implemented by hooks which deliver entries in the background (e.g. SlackAPIHook).
+// Flush waits for pending deliveries, returning an error describing what is still undelivered
+// if ctx is done first
+type HookFlusher interface {
+	Flush(ctx context.Context) error
+}
+
+// Flush waits until the async queue and the pending deliveries of all hooks of the default Logger
+// are drained, or ctx is done
+func Flush(ctx context.Context) error {
+	return defaultLogger.Flush(ctx)
+}
+
+// CloseLogContext is CloseLog with a deadline.
+// It drains the async queue and all hooks of the default Logger, returning an error
+// describing what was still undelivered if ctx is done first
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+//	defer cancel()
+//	if err := logger.CloseLogContext(ctx); err != nil {
+//		fmt.Println("Some logs were not delivered:", err)
+//	}
+func CloseLogContext(ctx context.Context) error {
+	return defaultLogger.CloseContext(ctx)
+}
+
+// Flush waits until the async queue and the pending deliveries of all hooks are drained,
+// or ctx is done. The Logger remains usable
+func (l *Logger) Flush(ctx context.Context) error {
+	var errs []error
+
+	if q := l.async.Load(); q != nil {
+		if err := q.flush(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	errs = append(errs, l.flushHooks(ctx)...)
+	return errors.Join(errs...)
+}
+
+// CloseContext closes the async queue and drains it and all hooks,
+// returning an error describing what was still undelivered if ctx is done first
+func (l *Logger) CloseContext(ctx context.Context) error {
+	var errs []error
+
+	if q := l.async.Load(); q != nil {
+		// Close the queue so nothing else can be added and wait for *all* log processing to complete
+		if err := q.close(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// The async worker may have fired hooks right up to the end, so drain hooks last
+	errs = append(errs, l.flushHooks(ctx)...)
+	if len(errs) == 0 {
+		l.lr.Info("Logs gracefully shutdown")
+		errs = append(errs, l.flushHooks(ctx)...) // deliver the shutdown message too
+	}
+
+	errs = append(errs, l.closeHooks()...)
+	return errors.Join(errs...)
+}
+
+// closeHooks removes the managed hooks from the logrus instance and closes those holding
+// files, connections or senders, as Reconfigure does with retired hooks.
+// A later Reconfigure builds them anew
+func (l *Logger) closeHooks() (errs []error) {
+	l.cfgMu.Lock()
+	closing := l.hooks
+	levelHooks := make(logrus.LevelHooks)
+	for _, hook := range uniqueHooks(l.lr.Hooks) {
+		if !isManagedHook(closing, hook) {
+			levelHooks.Add(hook)
+		}
+	}
+	l.lr.ReplaceHooks(levelHooks)
+	l.hooks = nil
+	l.setOutputs(nil) // the outputs are closed, so restore the logrus output
+	l.cfgMu.Unlock()
+
+	for _, mh := range closing {
+		if cl, ok := mh.hook.(io.Closer); ok {
+			if err := cl.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", mh.name, err))
+			}
+		}
+	}
+	return
+}
+
+// flushHooks flushes every hook implementing HookFlusher
+func (l *Logger) flushHooks(ctx context.Context) (errs []error) {
+	for _, hook := range l.hookSnapshot() {
+		if fl, ok := hook.(HookFlusher); ok {
+			if g, ok := hook.(*gatedHook); ok {
+				hook = g.Hook // name the hook itself in errors
+			}
+			if err := fl.Flush(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("%T: %w", hook, err))
+			}
+		}
+	}
+	return
+}
+
+// hookSnapshot returns the hooks of the logrus instance.
+// Reconfigure and the admin handler replace them holding cfgMu, so they are read under it too
+func (l *Logger) hookSnapshot() []logrus.Hook {
+	l.cfgMu.Lock()
+	defer l.cfgMu.Unlock()
+	return uniqueHooks(l.lr.Hooks)
+}
+
+// uniqueHooks returns each hook of lh once, as logrus registers a hook under each of its levels
+func uniqueHooks(lh logrus.LevelHooks) (hooks []logrus.Hook) {
+	for _, lvl := range logrus.AllLevels {
+	nextHook:
+		for _, hook := range lh[lvl] {
+			for _, seen := range hooks {
+				if sameHook(hook, seen) {
+					continue nextHook
+				}
+			}
+			hooks = append(hooks, hook)
+		}
+	}
+	return
+}
+
+// sameHook compares hooks without panicking on hooks of non-comparable types
+func sameHook(a, b logrus.Hook) bool {
+	ta, tb := reflect.TypeOf(a), reflect.TypeOf(b)
+	if ta != tb {
+		return false
+	}
+	if !ta.Comparable() {
+		switch ta.Kind() {
+		case reflect.Ptr, reflect.Map, reflect.Func, reflect.Chan:
+			return reflect.ValueOf(a).Pointer() == reflect.ValueOf(b).Pointer()
+		default: // e.g. a struct value holding a slice, which can't be told apart
+			return false
+		}
+	}
+	return a == b
+}
@@ -0,0 +1,164 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// deliveryHook delivers entries in the background after a delay, like the Slack API hook
+type deliveryHook struct {
+	delay     time.Duration
+	pending   atomic.Int64
+	delivered atomic.Int64
+}
+
+func (h *deliveryHook) Levels() []logrus.Level { return logrus.AllLevels }
+
+func (h *deliveryHook) Fire(entry *logrus.Entry) error {
+	h.pending.Add(1)
+	go func() {
+		time.Sleep(h.delay)
+		h.delivered.Add(1)
+		h.pending.Add(-1)
+	}()
+	return nil
+}
+
+func (h *deliveryHook) Flush(ctx context.Context) error {
+	for h.pending.Load() > 0 {
+		select {
+		case <-time.After(time.Millisecond):
+		case <-ctx.Done():
+			return fmt.Errorf("%d deliveries pending: %w", h.pending.Load(), ctx.Err())
+		}
+	}
+	return nil
+}
+
+func TestFlushDrainsQueueAndHooks(t *testing.T) {
+	lgr := New(LogConfig{LogLevel: "debug"})
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+
+	hook := &deliveryHook{delay: 20 * time.Millisecond}
+	lgr.Logrus().AddHook(hook)
+
+	for range 10 {
+		lgr.Async("warn", "Disk usage high")
+	}
+
+	if err := lgr.Flush(context.Background()); err != nil {
+		t.Fatalf("unexpected flush error: %v", err)
+	}
+	if got := hook.delivered.Load(); got != 10 {
+		t.Errorf("expected 10 deliveries after Flush, got %d", got)
+	}
+
+	if err := lgr.CloseContext(context.Background()); err != nil {
+		t.Fatalf("unexpected close error: %v", err)
+	}
+	if got := hook.delivered.Load(); got != 11 { // including the shutdown message
+		t.Errorf("expected 11 deliveries after close, got %d", got)
+	}
+}
+
+func TestCloseContextDeadline(t *testing.T) {
+	lgr := New(LogConfig{LogLevel: "debug"})
+	var out bytes.Buffer
+	lgr.Logrus().SetOutput(&out)
+
+	hook := &deliveryHook{delay: time.Second}
+	lgr.Logrus().AddHook(hook)
+
+	lgr.Error("Payment gateway down")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := lgr.CloseContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "1 deliveries pending") {
+		t.Errorf("expected the error to describe the undelivered entries, got %q", err)
+	}
+	if strings.Contains(out.String(), "gracefully") {
+		t.Errorf("expected no graceful shutdown message when closing failed, got %s", out.String())
+	}
+}
+
+// valueHook can't be compared, as it holds a slice
+type valueHook struct{ lv []logrus.Level }
+
+func (h valueHook) Levels() []logrus.Level     { return h.lv }
+func (h valueHook) Fire(_ *logrus.Entry) error { return nil }
+
+func TestFlushWithUncomparableHook(t *testing.T) {
+	lgr := New(LogConfig{})
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+	lgr.Logrus().AddHook(valueHook{lv: logrus.AllLevels})
+
+	lgr.Info("Order shipped")
+	if err := lgr.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	lgr.Reconfigure(LogConfig{LogLevel: "warn"})
+	if err := lgr.CloseContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFlushWhileReconfiguring(t *testing.T) {
+	lgr := New(LogConfig{})
+	defer lgr.Close()
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 20 {
+			lgr.Reconfigure(LogConfig{LogLevel: []string{"info", "warn"}[i%2]})
+		}
+	}()
+	for range 20 {
+		if err := lgr.Flush(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+}
+
+func TestCloseContextClosesManagedHooks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	lgr := New(LogConfig{FileLogCfg: FileLogCfg{Enabled: true, Path: path}})
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+	hook := &deliveryHook{}
+	lgr.Logrus().AddHook(hook)
+
+	lgr.Info("Order shipped")
+	if err := lgr.CloseContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	lgr.Info("After close")
+	_ = hook.Flush(context.Background())
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "Order shipped") || strings.Contains(string(data), "After close") {
+		t.Errorf("expected the file hook to be closed by CloseContext, got %s", data)
+	}
+	if got := hook.delivered.Load(); got != 3 { // the unmanaged hook stays
+		t.Errorf("expected the unmanaged hook to receive 3 entries, got %d", got)
+	}
+}
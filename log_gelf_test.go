@@ -0,0 +1,190 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGELFUDPChunkedGzip(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	lgr := New(LogConfig{
+		LogLevel: "debug",
+		GELFCfg: GELFCfg{
+			Enabled: true, Address: pc.LocalAddr().String(), Host: "api-1",
+			ChunkSize: 200, LogLevel: "info",
+		},
+	})
+	defer lgr.Close()
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+
+	details := strings.Repeat("stack frame\n", 200)
+	lgr.Warn("Slow query\n"+details, "duration_ms", 1200, "id", "q-7", "db name", "orders")
+
+	msg := readGELFUDP(t, pc)
+	expected := map[string]any{
+		"version":       "1.1",
+		"host":          "api-1",
+		"short_message": "Slow query",
+		"full_message":  "Slow query\n" + details,
+		"level":         float64(4),
+		"_duration_ms":  float64(1200), // numbers stay numbers
+		"__id":          "q-7",         // _id is reserved
+		"_db_name":      "orders",
+	}
+	for k, v := range expected {
+		if msg[k] != v {
+			t.Errorf("expected %s=%v, got %v", k, v, msg[k])
+		}
+	}
+	if ts, ok := msg["timestamp"].(float64); !ok || time.Since(time.Unix(int64(ts), 0)) > time.Minute {
+		t.Errorf("unexpected timestamp %v", msg["timestamp"])
+	}
+}
+
+func TestGELFUDPZlib(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	lgr := New(LogConfig{
+		LogLevel: "debug",
+		GELFCfg:  GELFCfg{Enabled: true, Address: pc.LocalAddr().String(), Compression: "zlib"},
+	})
+	defer lgr.Close()
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+
+	lgr.Info("Cache warmed")
+	if msg := readGELFUDP(t, pc); msg["short_message"] != "Cache warmed" {
+		t.Errorf("unexpected message %v", msg)
+	}
+}
+
+func TestGELFTCPWithLocation(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	lgr := New(LogConfig{
+		LogLevel: "debug",
+		GELFCfg:  GELFCfg{Enabled: true, Network: "tcp", Address: ln.Addr().String()},
+	})
+	defer lgr.Close()
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+
+	lgr.LogErr(errors.New("payment declined"), "order_id", "A-1002")
+	lgr.Info("Retrying")
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	rd := bufio.NewReader(conn)
+
+	first := readGELFFrame(t, rd)
+	if first["short_message"] != "payment declined" || first["level"] != float64(3) || first["_order_id"] != "A-1002" {
+		t.Errorf("unexpected error message %v", first)
+	}
+	if file, _ := first["_file"].(string); !strings.HasSuffix(file, "log_gelf_test.go") || first["_line"] == nil {
+		t.Errorf("expected the error location in _file and _line, got %v:%v", first["_file"], first["_line"])
+	}
+
+	if second := readGELFFrame(t, rd); second["short_message"] != "Retrying" {
+		t.Errorf("unexpected second message %v", second)
+	}
+}
+
+func TestGELFChunkSizeTooSmall(t *testing.T) {
+	lgr := New(LogConfig{})
+	defer lgr.Close()
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+
+	changes := lgr.Reconfigure(LogConfig{GELFCfg: GELFCfg{Enabled: true, ChunkSize: 12}})
+	if !strings.Contains(strings.Join(changes, ","), "hook disabled: gelf: chunk size 12") {
+		t.Errorf("expected the GELF hook to be disabled, got %v", changes)
+	}
+}
+
+// readGELFUDP reads a GELF message, reassembling chunks and decompressing it
+func readGELFUDP(t *testing.T, pc net.PacketConn) map[string]any {
+	t.Helper()
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	var payload []byte
+	chunks := map[byte][]byte{}
+	for {
+		buf := make([]byte, 65536)
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf = buf[:n]
+
+		if buf[0] != 0x1e || buf[1] != 0x0f { // not chunked
+			payload = buf
+			break
+		}
+		chunks[buf[10]] = buf[12:]
+		if count := int(buf[11]); len(chunks) == count {
+			if count < 2 {
+				t.Errorf("expected a chunked message")
+			}
+			for i := range count {
+				payload = append(payload, chunks[byte(i)]...)
+			}
+			break
+		}
+	}
+
+	var rd io.Reader
+	var err error
+	switch {
+	case payload[0] == 0x1f && payload[1] == 0x8b:
+		rd, err = gzip.NewReader(bytes.NewReader(payload))
+	case payload[0] == 0x78:
+		rd, err = zlib.NewReader(bytes.NewReader(payload))
+	default:
+		rd = bytes.NewReader(payload)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var msg map[string]any
+	if err := json.NewDecoder(rd).Decode(&msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func readGELFFrame(t *testing.T, rd *bufio.Reader) map[string]any {
+	t.Helper()
+	frame, err := rd.ReadBytes(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg map[string]any
+	if err := json.Unmarshal(frame[:len(frame)-1], &msg); err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
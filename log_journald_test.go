@@ -0,0 +1,122 @@
+//go:build linux
+
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestJournald(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "journal.sock")
+	journal, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: sock, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer journal.Close()
+
+	lgr := New(LogConfig{
+		LogLevel:    "debug",
+		JournaldCfg: JournaldCfg{Enabled: true, SocketPath: sock, SyslogIdentifier: "billing", LogLevel: "info"},
+	})
+	defer lgr.Close()
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+
+	lgr.Debug("Below the journald level")
+	lgr.Warn("Card expiring", "customer-id", 42, "note", "line one\nline two")
+
+	fields := readJournalEntry(t, journal)
+	expected := map[string]string{
+		"MESSAGE":           "Card expiring",
+		"PRIORITY":          "4",
+		"SYSLOG_IDENTIFIER": "billing",
+		"CUSTOMER_ID":       "42",
+		"NOTE":              "line one\nline two",
+	}
+	for k, v := range expected {
+		if fields[k] != v {
+			t.Errorf("expected %s=%q, got %q", k, v, fields[k])
+		}
+	}
+
+	// Fields named like the ones the hook writes are kept apart
+	lgr.Info("Card updated", "message", "from user", "priority", "high")
+	fields = readJournalEntry(t, journal)
+	if fields["MESSAGE"] != "Card updated" || fields["PRIORITY"] != "6" ||
+		fields["FIELDS_MESSAGE"] != "from user" || fields["FIELDS_PRIORITY"] != "high" {
+		t.Errorf("expected the reserved fields prefixed, got %v", fields)
+	}
+
+	lgr.LogErr(errors.New("payment declined"))
+	fields = readJournalEntry(t, journal)
+	if fields["PRIORITY"] != "3" || !strings.HasSuffix(fields["CODE_FILE"], "log_journald_test.go") ||
+		fields["CODE_LINE"] == "" || fields["CODE_FUNC"] == "" {
+		t.Errorf("expected an error with its code location, got %v", fields)
+	}
+
+	// Too large for a datagram, so it is passed as a memfd
+	big := strings.Repeat("x", 4<<20)
+	lgr.Info("Large payload", "payload", big)
+	fields = readJournalEntry(t, journal)
+	if fields["MESSAGE"] != "Large payload" || fields["PAYLOAD"] != big {
+		t.Errorf("expected the large entry intact, got a payload of %d bytes", len(fields["PAYLOAD"]))
+	}
+}
+
+// readJournalEntry receives an entry in journald's native format, from a datagram or a passed descriptor
+func readJournalEntry(t *testing.T, conn *net.UnixConn) map[string]string {
+	t.Helper()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	buf, oob := make([]byte, 1<<16), make([]byte, syscall.CmsgSpace(4))
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := buf[:n]
+
+	if oobn > 0 {
+		msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+		if err != nil {
+			t.Fatal(err)
+		}
+		fds, err := syscall.ParseUnixRights(&msgs[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		f := os.NewFile(uintptr(fds[0]), "entry")
+		defer f.Close()
+		info, err := f.Stat()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = make([]byte, info.Size())
+		if _, err := f.ReadAt(data, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fields := map[string]string{}
+	for len(data) > 0 {
+		nl := bytes.IndexByte(data, '\n')
+		line := string(data[:nl])
+		data = data[nl+1:]
+
+		if name, value, ok := strings.Cut(line, "="); ok {
+			fields[name] = value
+			continue
+		}
+		size := binary.LittleEndian.Uint64(data[:8])
+		fields[line] = string(data[8 : 8+size])
+		data = data[8+size+1:]
+	}
+	return fields
+}
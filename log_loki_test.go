@@ -0,0 +1,163 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rohanthewiz/logger/hooks/loki_log"
+	"github.com/sirupsen/logrus"
+)
+
+type lokiPush struct {
+	Streams []struct {
+		Stream map[string]string `json:"stream"`
+		Values [][2]string       `json:"values"`
+	} `json:"streams"`
+}
+
+// lokiStandIn records pushes, failing the first failures of them with 503
+type lokiStandIn struct {
+	mu       sync.Mutex
+	pushes   []lokiPush
+	failures int
+	tenant   string
+}
+
+func (ls *lokiStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+
+	if r.URL.Path != loki_log.PushPath || r.Header.Get("Content-Encoding") != "gzip" {
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+		return
+	}
+	if ls.failures > 0 {
+		ls.failures--
+		http.Error(w, "ingester unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	gz, err := gzip.NewReader(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var push lokiPush
+	if err := json.NewDecoder(gz).Decode(&push); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ls.tenant = r.Header.Get("X-Scope-OrgID")
+	ls.pushes = append(ls.pushes, push)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func TestLoki(t *testing.T) {
+	loki := &lokiStandIn{}
+	srv := httptest.NewServer(loki)
+	defer srv.Close()
+
+	lgr := New(LogConfig{
+		LogLevel: "debug",
+		LokiCfg: LokiCfg{
+			Enabled: true, URL: srv.URL, TenantID: "team-a",
+			Labels:      map[string]string{"service": "billing"},
+			LabelFields: []string{"level", "region"},
+			BatchWait:   time.Hour, // only Flush sends
+		},
+	})
+	defer lgr.Close()
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+
+	lgr.Info("Order shipped", "region", "eu", "order_id", "A-1001")
+	lgr.Info("Order shipped", "region", "us", "order_id", "A-1002")
+	lgr.Error("Payment declined", "region", "eu", "order_id", "A-1003")
+	lgr.Info("Invoice sent", "region", "eu", "order_id", "A-1004")
+
+	if err := lgr.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	loki.mu.Lock()
+	defer loki.mu.Unlock()
+
+	if len(loki.pushes) != 1 {
+		t.Fatalf("expected a single batched push, got %d", len(loki.pushes))
+	}
+	if loki.tenant != "team-a" {
+		t.Errorf("expected the tenant header, got %q", loki.tenant)
+	}
+
+	streams := map[string][][2]string{}
+	for _, s := range loki.pushes[0].Streams {
+		if s.Stream["service"] != "billing" {
+			t.Errorf("expected the static label on every stream, got %v", s.Stream)
+		}
+		streams[s.Stream["level"]+"/"+s.Stream["region"]] = s.Values
+	}
+	if len(streams) != 3 || len(streams["info/eu"]) != 2 || len(streams["info/us"]) != 1 || len(streams["error/eu"]) != 1 {
+		t.Fatalf("unexpected streams %v", streams)
+	}
+
+	var line map[string]any
+	if err := json.Unmarshal([]byte(streams["info/eu"][1][1]), &line); err != nil {
+		t.Fatal(err)
+	}
+	if line["msg"] != "Invoice sent" || line["order_id"] != "A-1004" || line["region"] != nil {
+		t.Errorf("expected the line without label fields, got %v", line)
+	}
+}
+
+func TestLokiRetriesWithBackoff(t *testing.T) {
+	loki := &lokiStandIn{failures: 2}
+	srv := httptest.NewServer(loki)
+	defer srv.Close()
+
+	hook := loki_log.NewLokiHook(srv.URL+loki_log.PushPath, nil, []string{"level"}, logrus.AllLevels)
+	hook.MinBackoff = 5 * time.Millisecond
+
+	lgr := logrus.New()
+	lgr.SetOutput(&bytes.Buffer{})
+	lgr.AddHook(hook)
+	lgr.Warn("Disk usage high")
+
+	if err := hook.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	loki.mu.Lock()
+	defer loki.mu.Unlock()
+	if loki.failures != 0 || len(loki.pushes) != 1 || len(loki.pushes[0].Streams[0].Values) != 1 {
+		t.Errorf("expected the push to succeed after retrying, got %+v", loki.pushes)
+	}
+}
+
+func TestLokiFallbackLabel(t *testing.T) {
+	loki := &lokiStandIn{}
+	srv := httptest.NewServer(loki)
+	defer srv.Close()
+
+	hook := loki_log.NewLokiHook(srv.URL, nil, []string{"region"}, logrus.AllLevels)
+
+	lgr := logrus.New()
+	lgr.SetOutput(&bytes.Buffer{})
+	lgr.AddHook(hook)
+	lgr.Warn("Disk usage high") // no region, so no labels
+
+	if err := hook.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	loki.mu.Lock()
+	defer loki.mu.Unlock()
+	if len(loki.pushes) != 1 || loki.pushes[0].Streams[0].Stream["service_name"] == "" {
+		t.Errorf("expected a service_name label on a stream without labels, got %+v", loki.pushes)
+	}
+}
@@ -0,0 +1,175 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// loggerNameKey is the field holding the name of a named Logger
+const loggerNameKey = "logger"
+
+// levelRules resolves the level of hierarchically named Loggers
+type levelRules struct {
+	base    logrus.Level            // level for unnamed Loggers and names without a matching rule
+	outputs logrus.Level            // most verbose level set on Outputs, which may be more verbose than base
+	rules   map[string]logrus.Level // level per name, e.g. "db" -> debug applies to "db" and "db.pool"
+}
+
+// Named returns a child of the default Logger with the given name. See (*Logger).Named
+func Named(name string) *Logger {
+	return defaultLogger.Named(name)
+}
+
+// Named returns a child Logger with the given name, which is added to entries as the "logger" field.
+// Naming a named Logger appends with a dot, so Named("db").Named("pool") is "db.pool".
+// The level of a named Logger comes from the most specific match in LogConfig.ComponentLevels
+// Example:
+//
+//	// LogConfig{ComponentLevels: "db=debug,http.client=warn,*=info"}
+//	poolLog := logger.Named("db.pool")
+//	poolLog.Debug("Connection acquired", "idle", 4) // logged, "db" is at debug
+func (l *Logger) Named(name string) *Logger {
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+	child := l.With(loggerNameKey, name)
+	child.name = name
+	return child
+}
+
+// Name returns the name of the Logger (see Named)
+func (l *Logger) Name() string {
+	return l.name
+}
+
+// levelEnabled reports whether entries of level are logged by this Logger, taking its name into account.
+// Outputs with a level of their own may ask for more verbose entries, which the others filter out (see levelFiltered)
+func (l *Logger) levelEnabled(level logrus.Level) bool {
+	lr := l.levels.Load()
+	if lr == nil {
+		return l.lr.IsLevelEnabled(level)
+	}
+	return level <= max(lr.levelFor(l.name), lr.outputs)
+}
+
+// levelFiltered reports whether entry is more verbose than the level of the Logger it comes from (see withName).
+// As the logrus level is lowered to the most verbose ComponentLevels rule or output, entries logged on
+// the logrus instance directly (e.g. by other packages) and those only meant for an output are filtered on their way out
+func (l *Logger) levelFiltered(entry *logrus.Entry) bool {
+	lr := l.levels.Load()
+	if lr == nil {
+		return false
+	}
+	return entry.Level > lr.levelFor(entryName(entry))
+}
+
+// loggerNameCtxKey is the context key of the name of the Logger an entry comes from
+type loggerNameCtxKey struct{}
+
+// withName returns an entry with flds, carrying the name of a named Logger in its context.
+// The "logger" field is for display only, as callers may set it themselves
+func (l *Logger) withName(flds logrus.Fields) *logrus.Entry {
+	entry := l.lr.WithFields(flds)
+	if l.name != "" {
+		entry = entry.WithContext(context.WithValue(context.Background(), loggerNameCtxKey{}, l.name))
+	}
+	return entry
+}
+
+// entryName returns the name of the Logger entry comes from, empty for unnamed Loggers and other sources
+func entryName(entry *logrus.Entry) string {
+	if entry.Context == nil {
+		return ""
+	}
+	name, _ := entry.Context.Value(loggerNameCtxKey{}).(string)
+	return name
+}
+
+// levelGate is the Logger's formatter, which leaves out entries filtered by levelFiltered
+type levelGate struct {
+	logrus.Formatter
+	l *Logger
+}
+
+func (g levelGate) Format(entry *logrus.Entry) ([]byte, error) {
+	if g.l.levelFiltered(entry) {
+		return nil, nil
+	}
+	return g.Formatter.Format(entry)
+}
+
+// gatedHook is how managed hooks are registered with the logrus instance.
+// It leaves out entries filtered by levelFiltered, which are only meant for more verbose outputs or components
+type gatedHook struct {
+	logrus.Hook
+	l *Logger
+}
+
+func (g *gatedHook) Fire(entry *logrus.Entry) error {
+	if g.l.levelFiltered(entry) {
+		return nil
+	}
+	return g.Hook.Fire(entry)
+}
+
+// Flush flushes the hook if it delivers in the background
+func (g *gatedHook) Flush(ctx context.Context) error {
+	if fl, ok := g.Hook.(HookFlusher); ok {
+		return fl.Flush(ctx)
+	}
+	return nil
+}
+
+// levelFor returns the level for name, from the most specific rule matching name or a parent of it
+func (r *levelRules) levelFor(name string) logrus.Level {
+	for name != "" {
+		if lvl, ok := r.rules[name]; ok {
+			return lvl
+		}
+		i := strings.LastIndex(name, ".")
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return r.base
+}
+
+// mostVerbose returns the most verbose level of all rules and outputs, which the logrus instance must let through
+func (r *levelRules) mostVerbose() logrus.Level {
+	lvl := max(r.base, r.outputs)
+	for _, rl := range r.rules {
+		lvl = max(lvl, rl)
+	}
+	return lvl
+}
+
+// parseComponentLevels parses a spec like "db=debug,http.client=warn,*=info".
+// The "*" entry, if present, is returned separately as the default level
+func parseComponentLevels(spec string) (rules map[string]logrus.Level, dflt string, err error) {
+	rules = map[string]logrus.Level{}
+
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		name, levelStr, ok := strings.Cut(item, "=")
+		name, levelStr = strings.TrimSpace(name), strings.ToLower(strings.TrimSpace(levelStr))
+		lvl, known := logrusLevels[levelStr]
+		if !ok || name == "" || !known {
+			return nil, "", fmt.Errorf("invalid component level %q, expected name=level", item)
+		}
+
+		if name == "*" {
+			dflt = levelStr
+			continue
+		}
+		rules[name] = lvl
+	}
+	return
+}
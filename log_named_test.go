@@ -0,0 +1,129 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestNamedLoggerLevels(t *testing.T) {
+	lgr := New(LogConfig{
+		Formatter:       "json",
+		LogLevel:        "debug",
+		ComponentLevels: "db=debug, http.client=warn, *=info",
+	})
+	defer lgr.Close()
+
+	var out bytes.Buffer
+	lgr.Logrus().SetOutput(&out)
+
+	pool := lgr.Named("db").Named("pool")
+	client := lgr.Named("http.client")
+	server := lgr.Named("http.server")
+
+	pool.Debug("Connection acquired")
+	client.Info("Request sent")
+	client.Err(errors.New("request timed out"))
+	server.Debug("Route matched")
+	server.Info("Listening")
+	lgr.Debug("Root debug")
+	lgr.Info("Root info")
+
+	for _, exp := range []string{`"logger":"db.pool","msg":"Connection acquired"`,
+		`"logger":"http.client"`, `"msg":"request timed out"`,
+		`"logger":"http.server","msg":"Listening"`, `"msg":"Root info"`} {
+		if !strings.Contains(out.String(), exp) {
+			t.Errorf("expected %s in output: %s", exp, out.String())
+		}
+	}
+	for _, unexp := range []string{"Request sent", "Route matched", "Root debug"} {
+		if strings.Contains(out.String(), unexp) {
+			t.Errorf("did not expect %q in output: %s", unexp, out.String())
+		}
+	}
+
+	if pool.Name() != "db.pool" {
+		t.Errorf("expected name db.pool, got %q", pool.Name())
+	}
+	if lvl := lgr.Logrus().GetLevel(); lvl != logrus.DebugLevel {
+		t.Errorf("expected logrus to let the most verbose level through, got %s", lvl)
+	}
+	if lvl := lgr.GetLogLevel(); lvl != "info" {
+		t.Errorf("expected the \"*\" level to be the base level, got %s", lvl)
+	}
+
+	rules := lgr.levels.Load()
+	for name, exp := range map[string]logrus.Level{
+		"db": logrus.DebugLevel, "db.pool.conn": logrus.DebugLevel, "dbx": logrus.InfoLevel,
+		"http": logrus.InfoLevel, "http.client.tls": logrus.WarnLevel, "": logrus.InfoLevel,
+	} {
+		if lvl := rules.levelFor(name); lvl != exp {
+			t.Errorf("levelFor(%q) = %s, expected %s", name, lvl, exp)
+		}
+	}
+}
+
+func TestComponentLevelsFilterDirectLogrusEntries(t *testing.T) {
+	lgr := New(LogConfig{Formatter: "json", ComponentLevels: "db=debug,*=info"})
+	defer lgr.Close()
+
+	var out bytes.Buffer
+	lgr.Logrus().SetOutput(&out)
+
+	lgr.Named("db").Debug("Query planned")
+	lgr.Logrus().Debug("Third party debug")
+	lgr.Logrus().Info("Third party info")
+
+	if !strings.Contains(out.String(), "Query planned") || !strings.Contains(out.String(), "Third party info") {
+		t.Errorf("expected the db debug and the info entries, got %s", out.String())
+	}
+	if strings.Contains(out.String(), "Third party debug") {
+		t.Errorf("expected debug entries without a component to be left out, got %s", out.String())
+	}
+
+	// Outputs replace the logrus output, so are filtered alike
+	var console bytes.Buffer
+	withOutputs := New(LogConfig{
+		ComponentLevels: "db=debug,*=info",
+		Outputs:         []OutputCfg{{Name: "console", Writer: &console, Formatter: "json"}},
+	})
+	defer withOutputs.Close()
+
+	withOutputs.Named("db").Debug("Index used")
+	withOutputs.Logrus().Debug("Third party debug")
+
+	if !strings.Contains(console.String(), "Index used") || strings.Contains(console.String(), "Third party debug") {
+		t.Errorf("expected outputs to be filtered alike, got %s", console.String())
+	}
+}
+
+func TestComponentLevelsFilterHooks(t *testing.T) {
+	logCh := make(chan string, 10)
+	var console bytes.Buffer
+	lgr := New(LogConfig{
+		ComponentLevels: "db=debug,*=info",
+		LogChanCfg:      LogChanCfg{Enabled: true, Ch: logCh, LogLevel: "debug"},
+		Outputs: []OutputCfg{
+			{Name: "console", Writer: &console},
+			{Name: "debug", Writer: &bytes.Buffer{}, LogLevel: "debug"}, // lets unnamed debug entries through the gate
+		},
+	})
+	defer lgr.Close()
+
+	lgr.Named("db").Debug("Query planned")
+	lgr.Logrus().Debug("Third party debug")
+	lgr.Debug("Spoofed component", "logger", "db") // the field doesn't make it a db entry
+
+	if n := len(logCh); n != 1 {
+		t.Fatalf("expected only the db debug entry on the log chan hook, got %d entries", n)
+	}
+	if msg := <-logCh; !strings.Contains(msg, "Query planned") {
+		t.Errorf("expected the db debug entry, got %s", msg)
+	}
+	if out := console.String(); !strings.Contains(out, "Query planned") || strings.Contains(out, "Spoofed component") {
+		t.Errorf("expected the debug entry with a logger field to be left out, got %s", out)
+	}
+}
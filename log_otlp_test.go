@@ -0,0 +1,279 @@
+package logger
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	testTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testSpanID  = "00f067aa0ba902b7"
+)
+
+// otlpCollector records the bodies of export requests
+type otlpCollector struct {
+	mu          sync.Mutex
+	bodies      [][]byte
+	contentType string
+}
+
+func (c *otlpCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var rd io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		rd = gz
+	}
+	body, _ := io.ReadAll(rd)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if r.URL.Path != "/v1/logs" {
+		http.Error(w, "unexpected path", http.StatusNotFound)
+		return
+	}
+	c.bodies = append(c.bodies, body)
+	c.contentType = r.Header.Get("Content-Type")
+}
+
+func TestOTLPJSON(t *testing.T) {
+	collector := &otlpCollector{}
+	srv := httptest.NewServer(collector)
+	defer srv.Close()
+
+	lgr := New(LogConfig{
+		LogLevel: "debug",
+		OTLPCfg: OTLPCfg{
+			Enabled: true, Endpoint: srv.URL, Gzip: true,
+			ServiceName:        "billing",
+			ResourceAttributes: map[string]string{"deployment.environment": "prod"},
+		},
+	})
+	defer lgr.Close()
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+
+	lgr.Warn("Card expiring", "days", 3, "ratio", 0.5, "retry", true,
+		"trace_id", testTraceID, "span_id", testSpanID)
+	if err := lgr.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	if len(collector.bodies) != 1 || collector.contentType != "application/json" {
+		t.Fatalf("expected one JSON export, got %d (%s)", len(collector.bodies), collector.contentType)
+	}
+
+	type keyValue struct {
+		Key   string         `json:"key"`
+		Value map[string]any `json:"value"`
+	}
+	var req struct {
+		ResourceLogs []struct {
+			Resource struct {
+				Attributes []keyValue `json:"attributes"`
+			} `json:"resource"`
+			ScopeLogs []struct {
+				LogRecords []struct {
+					TimeUnixNano   string         `json:"timeUnixNano"`
+					SeverityNumber int            `json:"severityNumber"`
+					SeverityText   string         `json:"severityText"`
+					Body           map[string]any `json:"body"`
+					Attributes     []keyValue     `json:"attributes"`
+					TraceID        string         `json:"traceId"`
+					SpanID         string         `json:"spanId"`
+				} `json:"logRecords"`
+			} `json:"scopeLogs"`
+		} `json:"resourceLogs"`
+	}
+	if err := json.Unmarshal(collector.bodies[0], &req); err != nil {
+		t.Fatal(err)
+	}
+
+	resource := map[string]any{}
+	for _, kv := range req.ResourceLogs[0].Resource.Attributes {
+		resource[kv.Key] = kv.Value["stringValue"]
+	}
+	if resource["service.name"] != "billing" || resource["deployment.environment"] != "prod" {
+		t.Errorf("unexpected resource attributes %v", resource)
+	}
+
+	rec := req.ResourceLogs[0].ScopeLogs[0].LogRecords[0]
+	if rec.SeverityNumber != 13 || rec.SeverityText != "WARN" || rec.Body["stringValue"] != "Card expiring" || rec.TimeUnixNano == "" {
+		t.Errorf("unexpected record %+v", rec)
+	}
+	if rec.TraceID != testTraceID || rec.SpanID != testSpanID {
+		t.Errorf("expected the trace context, got %q / %q", rec.TraceID, rec.SpanID)
+	}
+
+	attrs := map[string]map[string]any{}
+	for _, kv := range rec.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	if attrs["days"]["intValue"] != "3" || attrs["ratio"]["doubleValue"] != 0.5 || attrs["retry"]["boolValue"] != true {
+		t.Errorf("unexpected attributes %v", attrs)
+	}
+	if _, ok := attrs["trace_id"]; ok {
+		t.Error("expected trace_id not to be repeated as an attribute")
+	}
+}
+
+func TestOTLPJSONNonFiniteFloats(t *testing.T) {
+	collector := &otlpCollector{}
+	srv := httptest.NewServer(collector)
+	defer srv.Close()
+
+	lgr := New(LogConfig{OTLPCfg: OTLPCfg{Enabled: true, Endpoint: srv.URL}})
+	defer lgr.Close()
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+
+	// Fields set on the logrus instance directly reach the hook as floats
+	lgr.Logrus().WithFields(logrus.Fields{"score": math.NaN(), "high": math.Inf(1), "low": math.Inf(-1)}).Warn("Scores computed")
+	if err := lgr.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	if len(collector.bodies) != 1 {
+		t.Fatalf("expected the batch to be exported, got %d requests", len(collector.bodies))
+	}
+	for _, exp := range []string{`{"doubleValue":"NaN"}`, `{"doubleValue":"Infinity"}`, `{"doubleValue":"-Infinity"}`} {
+		if !bytes.Contains(collector.bodies[0], []byte(exp)) {
+			t.Errorf("expected %s in %s", exp, collector.bodies[0])
+		}
+	}
+}
+
+func TestOTLPJSONLargeUint(t *testing.T) {
+	collector := &otlpCollector{}
+	srv := httptest.NewServer(collector)
+	defer srv.Close()
+
+	lgr := New(LogConfig{OTLPCfg: OTLPCfg{Enabled: true, Endpoint: srv.URL}})
+	defer lgr.Close()
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+
+	lgr.Warn("Checksum mismatch", "expected", uint64(math.MaxUint64), "actual", uint64(42))
+	if err := lgr.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	if len(collector.bodies) != 1 {
+		t.Fatalf("expected the batch to be exported, got %d requests", len(collector.bodies))
+	}
+	for _, exp := range []string{`{"stringValue":"18446744073709551615"}`, `{"intValue":"42"}`} {
+		if !bytes.Contains(collector.bodies[0], []byte(exp)) {
+			t.Errorf("expected %s in %s", exp, collector.bodies[0])
+		}
+	}
+}
+
+func TestOTLPProtobuf(t *testing.T) {
+	collector := &otlpCollector{}
+	srv := httptest.NewServer(collector)
+	defer srv.Close()
+
+	lgr := New(LogConfig{
+		LogLevel: "debug",
+		OTLPCfg:  OTLPCfg{Enabled: true, Endpoint: srv.URL + "/v1/logs", Protocol: "http/protobuf", ServiceName: "billing"},
+	})
+	defer lgr.Close()
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+
+	lgr.Error("Payment declined", "amount", 99.5, "attempt", 2, "checksum", uint64(math.MaxUint64), "trace_id", testTraceID)
+	if err := lgr.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	collector.mu.Lock()
+	defer collector.mu.Unlock()
+	if len(collector.bodies) != 1 || collector.contentType != "application/x-protobuf" {
+		t.Fatalf("expected one protobuf export, got %d (%s)", len(collector.bodies), collector.contentType)
+	}
+
+	resourceLogs := pbFields(t, collector.bodies[0])[1][0].bytes
+	rl := pbFields(t, resourceLogs)
+
+	resourceAttr := pbFields(t, pbFields(t, rl[1][0].bytes)[1][0].bytes)
+	if string(resourceAttr[1][0].bytes) != "service.name" ||
+		string(pbFields(t, resourceAttr[2][0].bytes)[1][0].bytes) != "billing" {
+		t.Errorf("unexpected resource attribute %v", resourceAttr)
+	}
+
+	scopeLogs := pbFields(t, rl[2][0].bytes)
+	rec := pbFields(t, scopeLogs[2][0].bytes)
+
+	if rec[2][0].varint != 17 || string(rec[3][0].bytes) != "ERROR" {
+		t.Errorf("unexpected severity %d %q", rec[2][0].varint, rec[3][0].bytes)
+	}
+	if body := pbFields(t, rec[5][0].bytes); string(body[1][0].bytes) != "Payment declined" {
+		t.Errorf("unexpected body %q", body[1][0].bytes)
+	}
+	if hex.EncodeToString(rec[9][0].bytes) != testTraceID {
+		t.Errorf("unexpected trace id %x", rec[9][0].bytes)
+	}
+
+	attrs := map[string]map[int][]pbField{}
+	for _, a := range rec[6] {
+		kv := pbFields(t, a.bytes)
+		attrs[string(kv[1][0].bytes)] = pbFields(t, kv[2][0].bytes)
+	}
+	if math.Float64frombits(attrs["amount"][4][0].varint) != 99.5 || attrs["attempt"][3][0].varint != 2 {
+		t.Errorf("unexpected attributes %v", attrs)
+	}
+	if checksum := attrs["checksum"]; len(checksum[3]) != 0 || string(checksum[1][0].bytes) != "18446744073709551615" {
+		t.Errorf("expected a uint64 beyond int64 as a string, got %v", checksum)
+	}
+}
+
+// pbField is a decoded protobuf field; fixed64 values are kept in varint
+type pbField struct {
+	varint uint64
+	bytes  []byte
+}
+
+// pbFields decodes a protobuf message into its fields by number
+func pbFields(t *testing.T, data []byte) map[int][]pbField {
+	t.Helper()
+	fields := map[int][]pbField{}
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		data = data[n:]
+
+		var f pbField
+		switch tag & 7 {
+		case 0:
+			f.varint, n = binary.Uvarint(data)
+			data = data[n:]
+		case 1:
+			f.varint = binary.LittleEndian.Uint64(data)
+			data = data[8:]
+		case 2:
+			size, n := binary.Uvarint(data)
+			f.bytes = data[n : n+int(size)]
+			data = data[n+int(size):]
+		default:
+			t.Fatalf("unexpected wire type %d", tag&7)
+		}
+		fields[int(tag>>3)] = append(fields[int(tag>>3)], f)
+	}
+	return fields
+}
@@ -0,0 +1,160 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/rohanthewiz/logger/hooks/file_log"
+	"github.com/rohanthewiz/logger/internal/hookctl"
+	"github.com/sirupsen/logrus"
+)
+
+// outputHookPrefix starts the managed hook names of outputs, e.g. "output:console"
+const outputHookPrefix = "output:"
+
+// outputHook writes entries to one of the configured outputs with its own formatter
+type outputHook struct {
+	mu             sync.Mutex // serializes writes so lines from concurrent logs don't interleave
+	w              io.Writer
+	closer         io.Closer // set when the hook opened the writer itself
+	formatter      logrus.Formatter
+	AcceptedLevels []logrus.Level
+	Disabled       bool
+	ctl            hookctl.Control                // runtime changes of Disabled and AcceptedLevels
+	skip           func(entry *logrus.Entry) bool // set by the Logger to leave out entries, see levelGate
+	ownLevel       atomic.Bool                    // the output has a level of its own, so skip does not apply
+}
+
+func (h *outputHook) Levels() []logrus.Level {
+	return h.ctl.Levels(h.AcceptedLevels)
+}
+
+// IsEnabled reports whether the hook fires
+func (h *outputHook) IsEnabled() bool {
+	return h.ctl.Enabled(!h.Disabled)
+}
+
+// SetEnabled enables or disables the hook, safely while it fires
+func (h *outputHook) SetEnabled(enabled bool) {
+	h.ctl.SetEnabled(enabled)
+}
+
+// SetLevels changes the levels the hook accepts, safely while it fires
+func (h *outputHook) SetLevels(levels []logrus.Level) {
+	h.ctl.SetLevels(levels)
+	h.ownLevel.Store(true)
+}
+
+func (h *outputHook) Fire(entry *logrus.Entry) error {
+	if !h.IsEnabled() || (h.skip != nil && !h.ownLevel.Load() && h.skip(entry)) {
+		return nil
+	}
+
+	formatted, err := h.formatter.Format(entry)
+	if err != nil {
+		fmt.Println("Unable to format log entry for output:", err)
+		return nil // a failing output shouldn't fail the others
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, err = h.w.Write(formatted); err != nil {
+		fmt.Println("Unable to write log entry to output:", err)
+	}
+	return nil
+}
+
+// Flush syncs the output if it is a file
+func (h *outputHook) Flush(_ context.Context) error {
+	if s, ok := h.w.(interface{ Sync() error }); ok && h.closer != nil {
+		return s.Sync()
+	}
+	return nil
+}
+
+// Close closes a file opened for a Target path. Writers passed in are left to their owner
+func (h *outputHook) Close() error {
+	if h.closer != nil {
+		return h.closer.Close()
+	}
+	return nil
+}
+
+// outputSpecs lists a hook for each configured output
+func outputSpecs(outputs []OutputCfg) []hookSpec {
+	specs := make([]hookSpec, 0, len(outputs))
+	for i, out := range outputs {
+		name := out.Name
+		if name == "" {
+			name = strconv.Itoa(i + 1)
+		}
+		specs = append(specs, hookSpec{
+			name:    outputHookPrefix + name,
+			enabled: true,
+			cfg:     out,
+			build:   func() logrus.Hook { return newOutputHook(out) },
+		})
+	}
+	return specs
+}
+
+func newOutputHook(out OutputCfg) *outputHook {
+	h := &outputHook{
+		w:              out.Writer,
+		formatter:      outputFormatter(out.Formatter),
+		AcceptedLevels: AllowedLevels(logrus.TraceLevel), // without a level of its own, the Logger's levels apply
+	}
+	if lvl, ok := logrusLevels[out.LogLevel]; ok {
+		h.AcceptedLevels = AllowedLevels(lvl)
+		h.ownLevel.Store(true)
+	}
+
+	if h.w == nil {
+		switch strings.ToLower(out.Target) {
+		case "", "stderr":
+			h.w = os.Stderr
+		case "stdout":
+			h.w = os.Stdout
+		default:
+			rf, err := file_log.NewRotatingFile(file_log.RotatingFileCfg{Path: out.Target})
+			if err != nil {
+				fmt.Println("Unable to open log output:", err)
+				h.w, h.AcceptedLevels = io.Discard, []logrus.Level{}
+				break
+			}
+			h.w, h.closer = rf, rf
+		}
+	}
+	return h
+}
+
+// outputFormatter returns the formatter for an output: "json", "logfmt" (key=value, full timestamps)
+// or logrus' default "text"
+func outputFormatter(format string) logrus.Formatter {
+	switch format {
+	case "json":
+		return &logrus.JSONFormatter{}
+	case "logfmt":
+		return &logrus.TextFormatter{DisableColors: true, FullTimestamp: true}
+	default:
+		return &logrus.TextFormatter{}
+	}
+}
+
+// setOutputs silences the logrus output while outputs are configured, restoring it once they are removed
+func (l *Logger) setOutputs(outputs []OutputCfg) {
+	switch {
+	case len(outputs) > 0 && l.savedOut == nil:
+		l.savedOut = l.lr.Out
+		l.lr.SetOutput(io.Discard)
+	case len(outputs) == 0 && l.savedOut != nil:
+		l.lr.SetOutput(l.savedOut)
+		l.savedOut = nil
+	}
+}
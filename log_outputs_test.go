@@ -0,0 +1,124 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestOutputs(t *testing.T) {
+	var console, jsonOut, logfmtOut, main bytes.Buffer
+
+	cfg := LogConfig{
+		LogLevel: "debug",
+		Outputs: []OutputCfg{
+			{Name: "console", Writer: &console, Formatter: "text", LogLevel: "info"},
+			{Name: "json", Writer: &jsonOut, Formatter: "json"},
+			{Writer: &logfmtOut, Formatter: "logfmt", LogLevel: "error"},
+		},
+	}
+
+	lgr := New(LogConfig{LogLevel: "debug"})
+	defer lgr.Close()
+	lgr.Logrus().SetOutput(&main)
+	lgr.Reconfigure(cfg)
+
+	lgr.Debug("Cache warmed", "entries", 42)
+	lgr.Info("Order shipped", "order_id", "A-1001")
+	lgr.Error("Payment declined", "order_id", "A-1002")
+
+	if out := console.String(); strings.Contains(out, "Cache warmed") ||
+		!strings.Contains(out, `msg="Order shipped"`) || !strings.Contains(out, `msg="Payment declined"`) {
+		t.Errorf("expected info and above as text on the console output, got %s", out)
+	}
+
+	lines := strings.Split(strings.TrimSpace(jsonOut.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected all 3 entries in the json output, got %q", lines)
+	}
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("expected json, got %s", lines[0])
+	}
+	if entry["msg"] != "Cache warmed" || entry["entries"] != float64(42) {
+		t.Errorf("unexpected json entry %v", entry)
+	}
+
+	if out := logfmtOut.String(); strings.Contains(out, "Order shipped") ||
+		!strings.Contains(out, `level=error msg="Payment declined" order_id=A-1002`) {
+		t.Errorf("expected only errors in logfmt, got %s", out)
+	}
+
+	if main.Len() != 0 {
+		t.Errorf("expected the logrus output to be silenced, got %s", main.String())
+	}
+
+	// Dropping outputs removes their hooks and restores the logrus output
+	cfg.Outputs = cfg.Outputs[:1]
+	changes := lgr.Reconfigure(cfg)
+	for _, exp := range []string{"hook removed: output:json", "hook removed: output:3"} {
+		if !slices.Contains(changes, exp) {
+			t.Errorf("expected change %q in %v", exp, changes)
+		}
+	}
+
+	cfg.Outputs = nil
+	lgr.Reconfigure(cfg)
+	lgr.Info("Back to a single output")
+	if !strings.Contains(main.String(), "Back to a single output") {
+		t.Errorf("expected the logrus output to be restored, got %q", main.String())
+	}
+}
+
+func TestOutputMoreVerboseThanLogLevel(t *testing.T) {
+	var console, debugFile bytes.Buffer
+
+	lgr := New(LogConfig{
+		LogLevel: "info",
+		Outputs: []OutputCfg{
+			{Name: "console", Writer: &console},
+			{Name: "debug", Writer: &debugFile, Formatter: "json", LogLevel: "debug"},
+		},
+	})
+	defer lgr.Close()
+
+	lgr.Debug("Cache warmed")
+	lgr.Logrus().Debug("Pool resized")
+	lgr.Info("Order shipped")
+
+	if out := debugFile.String(); !strings.Contains(out, "Cache warmed") ||
+		!strings.Contains(out, "Pool resized") || !strings.Contains(out, "Order shipped") {
+		t.Errorf("expected debug entries in the debug output, got %s", out)
+	}
+	if out := console.String(); strings.Contains(out, "Cache warmed") || strings.Contains(out, "Pool resized") ||
+		!strings.Contains(out, "Order shipped") {
+		t.Errorf("expected only info and above on the console output, got %s", out)
+	}
+	if lvl := lgr.GetLogLevel(); lvl != "info" {
+		t.Errorf("expected the log level to stay info, got %s", lvl)
+	}
+}
+
+func TestVerboseOutputKeepsHooksAtLogLevel(t *testing.T) {
+	logCh := make(chan string, 10)
+
+	lgr := New(LogConfig{
+		LogLevel:   "warn",
+		Outputs:    []OutputCfg{{Name: "debug", Writer: &bytes.Buffer{}, LogLevel: "debug"}},
+		LogChanCfg: LogChanCfg{Enabled: true, Ch: logCh, LogLevel: "debug"},
+	})
+	defer lgr.Close()
+
+	lgr.Debug("Cache warmed")
+	lgr.Info("Order shipped")
+	lgr.Warn("Disk usage high")
+
+	if n := len(logCh); n != 1 {
+		t.Fatalf("expected only the warn entry on the log chan hook, got %d entries", n)
+	}
+	if msg := <-logCh; !strings.Contains(msg, "Disk usage high") {
+		t.Errorf("expected the warn entry, got %s", msg)
+	}
+}
@@ -1,123 +1,731 @@
 package logger
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/rohanthewiz/logger/hooks/elastic_log"
+	"github.com/rohanthewiz/logger/hooks/file_log"
+	"github.com/rohanthewiz/logger/hooks/fluent_log"
+	"github.com/rohanthewiz/logger/hooks/gelf_log"
+	"github.com/rohanthewiz/logger/hooks/journald_log"
 	"github.com/rohanthewiz/logger/hooks/log_chan"
+	"github.com/rohanthewiz/logger/hooks/loki_log"
+	"github.com/rohanthewiz/logger/hooks/otlp_log"
+	"github.com/rohanthewiz/logger/hooks/syslog_log"
+	"github.com/rohanthewiz/logger/hooks/webhook_log"
 	"github.com/rohanthewiz/logger/slack_api"
 	"github.com/rohanthewiz/logger/teams_log"
 	"github.com/sirupsen/logrus"
 )
 
-var logPrefix string
+// retiredHookFlushTimeout limits how long Reconfigure waits for replaced hooks to deliver pending entries
+const retiredHookFlushTimeout = 5 * time.Second
 
-func InitLog(logCfg LogConfig) {
-	initLogrus(logCfg)
+// managedHook is a hook built from the LogConfig, tracked so Reconfigure can swap it
+type managedHook struct {
+	name string // e.g. "teams", "slack_api", "log_chan"
+	cfg  any    // the sub-config the hook was built from
+	hook logrus.Hook
+}
 
-	logChanSize := logCfg.LogChanSize
-	if logChanSize == 0 {
-		logChanSize = defaultLogChannelSize
-	}
+// hookSpec describes how to build a hook from the LogConfig
+type hookSpec struct {
+	name    string
+	enabled bool
+	cfg     any
+	build   func() logrus.Hook
+	err     error // the config is invalid, so the hook is left out and the error logged
+}
 
-	logsChannel = make(chan [][]byte, logChanSize)
-	logsDone = make(chan bool)
+// InitLog configures the default Logger used by the package level functions.
+// It is safe to call more than once - see Reconfigure
+func InitLog(logCfg LogConfig) {
+	defaultLogger.Reconfigure(logCfg)
+}
 
-	go pollForLogs(logsChannel, logsDone) // start the listener
+// Reconfigure applies logCfg to the default Logger, returning a description of what changed
+func Reconfigure(logCfg LogConfig) (changes []string) {
+	return defaultLogger.Reconfigure(logCfg)
 }
 
+// CloseLog flushes async logs and pending hook deliveries of the default Logger.
+// Use CloseLogContext to limit how long to wait
 func CloseLog() {
-	logsWaitGroup.Wait()
-	// Close the channel so nothing else can be added and the log poller knows to start wrapping up
-	close(logsChannel)
-	<-logsDone // wait for *all* log processing to complete
-	logrus.Info("Logs gracefully shutdown")
+	defaultLogger.Close()
 }
 
-func initLogrus(logCfg LogConfig) {
-	logPrefix = logCfg.EnvPrefix
+// Close waits for all async logs to be processed and hooks to deliver pending entries.
+// Children created via With() share the queue of their parent, so close only the root Logger
+func (l *Logger) Close() {
+	if err := l.CloseContext(context.Background()); err != nil {
+		fmt.Println("Error closing logs:", err)
+	}
+}
 
-	if logCfg.LogLevel == "" {
-		logCfg.LogLevel = defaultLogLevel
+// Reconfigure applies logCfg to this Logger: prefix, formatter, level, hooks and the async queue.
+// The hook set is swapped in one step, so hooks never fire twice however often this is called.
+// Hooks whose config is unchanged are kept as is; replaced hooks are flushed of pending deliveries.
+// If the async queue settings change (or the Logger was closed) a new queue is started
+// and the previous one is drained and shut down.
+// It returns a description of each change, e.g. `level: "debug" -> "info"`
+func (l *Logger) Reconfigure(logCfg LogConfig) (changes []string) {
+	l.cfgMu.Lock()
+	defer l.cfgMu.Unlock()
+
+	logCfg = withConfigDefaults(logCfg)
+	prev := l.cfg
+
+	change := func(setting string, from, to any) {
+		changes = append(changes, fmt.Sprintf("%s: %q -> %q", setting, from, to))
 	}
 
-	SetLogFormat(logCfg.Formatter)
+	if logCfg.EnvPrefix != prev.EnvPrefix {
+		change("prefix", prev.EnvPrefix, logCfg.EnvPrefix)
+	}
+	l.setPrefix(logCfg.EnvPrefix)
+
+	if logCfg.Formatter != prev.Formatter {
+		change("formatter", prev.Formatter, logCfg.Formatter)
+	}
+	l.setLogFormat(logCfg.Formatter)
 
-	SetLogLevel(logCfg.LogLevel)
+	if logCfg.LogLevel != prev.LogLevel {
+		change("level", prev.LogLevel, logCfg.LogLevel)
+	}
+	if logCfg.ComponentLevels != prev.ComponentLevels {
+		change("component levels", prev.ComponentLevels, logCfg.ComponentLevels)
+	}
+
+	rules, dfltLevel, err := parseComponentLevels(logCfg.ComponentLevels)
+	if err != nil {
+		fmt.Println("Ignoring ComponentLevels:", err)
+	}
+	baseLevel := logCfg.LogLevel
+	if dfltLevel != "" {
+		baseLevel = dfltLevel // "*" overrides LogLevel
+	}
+	l.setLevels(baseLevel, rules, logCfg.Outputs)
 
 	// HOOKS
+	var hooks []managedHook
+	var retired []logrus.Hook
+	var invalid []hookSpec
 
-	// Teams Log
-	if logCfg.TeamsLogCfg.Enabled {
-		// Pass config down to local package
-		teams_log.SetTeamsCfg(teams_log.TeamsCfg{
-			Enabled:     logCfg.TeamsLogCfg.Enabled,
-			LogEndpoint: logCfg.TeamsLogCfg.Endpoint,
-			LogLevel:    logCfg.TeamsLogCfg.LogLevel,
-		})
+	var specNames []string
+
+	for _, spec := range hookSpecs(logCfg) {
+		specNames = append(specNames, spec.name)
+		old, hadOld := findManagedHook(l.hooks, spec.name)
 
-		if logCfg.TeamsLogCfg.LogLevel == "" {
-			logCfg.TeamsLogCfg.LogLevel = defaultTeamsLogLevel
+		switch {
+		case !spec.enabled:
+			if hadOld {
+				retired = append(retired, old.hook)
+				changes = append(changes, "hook removed: "+spec.name)
+			}
+		case spec.err != nil:
+			if hadOld {
+				retired = append(retired, old.hook)
+			}
+			invalid = append(invalid, spec)
+			changes = append(changes, fmt.Sprintf("hook disabled: %s: %v", spec.name, spec.err))
+		case hadOld && reflect.DeepEqual(old.cfg, spec.cfg):
+			hooks = append(hooks, old) // unchanged, keep the running hook
+		default:
+			hooks = append(hooks, managedHook{name: spec.name, cfg: spec.cfg, hook: spec.build()})
+			if hadOld {
+				retired = append(retired, old.hook)
+				changes = append(changes, "hook replaced: "+spec.name)
+			} else {
+				changes = append(changes, "hook added: "+spec.name)
+			}
 		}
+	}
 
-		logrus.AddHook(&teams_log.TeamsLogHook{
-			URL:            logCfg.TeamsLogCfg.Endpoint,
-			AcceptedLevels: teams_log.AllowedLevels(logrusLevels[strings.ToLower(logCfg.TeamsLogCfg.LogLevel)]),
-		})
+	// Hooks no longer configured at all, e.g. a removed output
+	for _, old := range l.hooks {
+		if _, ok := findManagedHook(hooks, old.name); !ok && !slices.Contains(specNames, old.name) {
+			retired = append(retired, old.hook)
+			changes = append(changes, "hook removed: "+old.name)
+		}
 	}
 
-	// LogChan hook — sends text-formatted log lines to a caller-provided channel
-	if logCfg.LogChanCfg.Enabled {
-		if logCfg.LogChanCfg.LogLevel == "" {
-			logCfg.LogChanCfg.LogLevel = defaultLogLevel
+	// Hooks added directly to the logrus instance are not ours to remove
+	levelHooks := make(logrus.LevelHooks)
+	for _, hook := range uniqueHooks(l.lr.Hooks) {
+		if !isManagedHook(l.hooks, hook) {
+			levelHooks.Add(hook)
+		}
+	}
+	for _, mh := range hooks {
+		if oh, ok := mh.hook.(*outputHook); ok {
+			if oh.skip == nil { // kept outputs already have it
+				oh.skip = l.levelFiltered // outputs replace the logrus output, so filter like its formatter
+			}
+			levelHooks.Add(oh)
+			continue
 		}
+		levelHooks.Add(&gatedHook{Hook: mh.hook, l: l})
+	}
+	l.lr.ReplaceHooks(levelHooks)
+	l.hooks = hooks
+	l.setOutputs(logCfg.Outputs)
 
-		acceptedLevel := logrusLevels[strings.ToLower(logCfg.LogChanCfg.LogLevel)]
-		acceptedLevels := log_chan.AllowedLevels(acceptedLevel)
+	// ASYNC QUEUE
+	oldQ := l.async.Load()
+	if oldQ == nil || oldQ.isClosed() || cap(oldQ.ch) != logCfg.LogChanSize ||
+		oldQ.policy != logCfg.AsyncPolicy || oldQ.caller != logCfg.AsyncCaller {
+		q := newAsyncQueue(logCfg.LogChanSize, logCfg.AsyncPolicy, logCfg.AsyncCaller)
+		go l.pollForLogs(q) // start the listener
+		l.async.Store(q)
 
-		hook := log_chan.NewLogChanHook(logCfg.LogChanCfg.Ch, acceptedLevels)
-		logrus.AddHook(hook)
+		if oldQ != nil && !oldQ.isClosed() {
+			changes = append(changes, fmt.Sprintf("async queue: %d/%s -> %d/%s",
+				cap(oldQ.ch), oldQ.policy, logCfg.LogChanSize, logCfg.AsyncPolicy))
+			_ = oldQ.close(context.Background()) // drain into the new configuration
+		}
 	}
 
-	// Slack API Log
-	if logCfg.SlackAPICfg.Enabled {
-		if logCfg.SlackAPICfg.LogLevel == "" {
-			logCfg.SlackAPICfg.LogLevel = defaultSlackAPILogLevel
+	l.cfg = logCfg
+
+	for _, spec := range invalid {
+		l.lr.WithError(spec.err).WithField("hook", spec.name).Error("Hook disabled, its config is invalid")
+	}
+
+	if len(retired) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), retiredHookFlushTimeout)
+		defer cancel()
+		for _, hook := range retired {
+			if fl, ok := hook.(HookFlusher); ok {
+				if err := fl.Flush(ctx); err != nil {
+					fmt.Printf("Error flushing replaced hook %T: %v\n", hook, err)
+				}
+			}
+			if cl, ok := hook.(io.Closer); ok { // e.g. release the file of a file hook
+				if err := cl.Close(); err != nil {
+					fmt.Printf("Error closing replaced hook %T: %v\n", hook, err)
+				}
+			}
 		}
+	}
 
-		// Convert string log level to logrus level
-		acceptedLevel := logrusLevels[strings.ToLower(logCfg.SlackAPICfg.LogLevel)]
-		acceptedLevels := AllowedLevels(acceptedLevel)
-		fmt.Println("Slack API acceptedLevels:", acceptedLevels)
+	return
+}
 
-		hook := slack_api.NewSlackAPIHook(
-			logCfg.SlackAPICfg.Token,
-			logCfg.SlackAPICfg.Channel,
-			acceptedLevels,
-			logCfg.SlackAPICfg.UseBlocks,
-		)
-		logrus.AddHook(hook)
+// withConfigDefaults fills in defaults and normalizes logCfg so configs can be compared
+func withConfigDefaults(logCfg LogConfig) LogConfig {
+	if strings.ToLower(logCfg.Formatter) == "json" {
+		logCfg.Formatter = "json"
+	} else {
+		logCfg.Formatter = "text"
+	}
+
+	if logCfg.LogLevel == "" {
+		logCfg.LogLevel = defaultLogLevel
+	}
+	logCfg.LogLevel = strings.ToLower(logCfg.LogLevel)
+
+	if logCfg.LogChanSize == 0 {
+		logCfg.LogChanSize = defaultLogChannelSize
+	}
+	logCfg.AsyncPolicy = asyncPolicy(logCfg.AsyncPolicy)
+
+	if logCfg.TeamsLogCfg.LogLevel == "" {
+		logCfg.TeamsLogCfg.LogLevel = defaultTeamsLogLevel
+	}
+	if logCfg.LogChanCfg.LogLevel == "" {
+		logCfg.LogChanCfg.LogLevel = defaultLogLevel
+	}
+	if logCfg.SlackAPICfg.LogLevel == "" {
+		logCfg.SlackAPICfg.LogLevel = defaultSlackAPILogLevel
+	}
+	if logCfg.FileLogCfg.LogLevel == "" {
+		logCfg.FileLogCfg.LogLevel = defaultLogLevel
+	}
+	if logCfg.FileLogCfg.Formatter == "" {
+		logCfg.FileLogCfg.Formatter = logCfg.Formatter
+	}
+	logCfg.FileLogCfg.Formatter = strings.ToLower(logCfg.FileLogCfg.Formatter)
+
+	if logCfg.SyslogCfg.Network == "" {
+		logCfg.SyslogCfg.Network = "udp"
+	}
+	if logCfg.SyslogCfg.Address == "" {
+		if logCfg.SyslogCfg.Network == "unix" {
+			logCfg.SyslogCfg.Address = "/dev/log"
+		} else {
+			logCfg.SyslogCfg.Address = "localhost:514"
+		}
+	}
+	if logCfg.SyslogCfg.Facility == "" {
+		logCfg.SyslogCfg.Facility = "user"
+	}
+	if logCfg.SyslogCfg.LogLevel == "" {
+		logCfg.SyslogCfg.LogLevel = defaultSyslogLogLevel
+	}
+	if logCfg.JournaldCfg.SocketPath == "" {
+		logCfg.JournaldCfg.SocketPath = journald_log.DefaultSocketPath
+	}
+	if logCfg.JournaldCfg.LogLevel == "" {
+		logCfg.JournaldCfg.LogLevel = defaultLogLevel
+	}
+
+	if logCfg.GELFCfg.Network == "" {
+		logCfg.GELFCfg.Network = "udp"
+	}
+	if logCfg.GELFCfg.Address == "" {
+		logCfg.GELFCfg.Address = "localhost:12201"
+	}
+	if logCfg.GELFCfg.Compression == "" {
+		logCfg.GELFCfg.Compression = gelf_log.CompressGzip
+	}
+	if logCfg.GELFCfg.ChunkSize == 0 {
+		logCfg.GELFCfg.ChunkSize = gelf_log.DefaultChunkSize
+	}
+	if logCfg.GELFCfg.LogLevel == "" {
+		logCfg.GELFCfg.LogLevel = defaultLogLevel
+	}
+
+	if logCfg.LokiCfg.LabelFields == nil {
+		logCfg.LokiCfg.LabelFields = []string{"level"}
+	}
+	if logCfg.LokiCfg.BatchSize == 0 {
+		logCfg.LokiCfg.BatchSize = loki_log.DefaultBatchSize
+	}
+	if logCfg.LokiCfg.BatchWait == 0 {
+		logCfg.LokiCfg.BatchWait = loki_log.DefaultBatchWait
+	}
+	if logCfg.LokiCfg.LogLevel == "" {
+		logCfg.LokiCfg.LogLevel = defaultLogLevel
+	}
+
+	if logCfg.OTLPCfg.Endpoint == "" {
+		logCfg.OTLPCfg.Endpoint = "http://localhost:4318"
+	}
+	if logCfg.OTLPCfg.Protocol == "" {
+		logCfg.OTLPCfg.Protocol = otlp_log.ProtocolJSON
+	}
+	if logCfg.OTLPCfg.BatchSize == 0 {
+		logCfg.OTLPCfg.BatchSize = otlp_log.DefaultBatchSize
+	}
+	if logCfg.OTLPCfg.BatchWait == 0 {
+		logCfg.OTLPCfg.BatchWait = otlp_log.DefaultBatchWait
+	}
+	if logCfg.OTLPCfg.LogLevel == "" {
+		logCfg.OTLPCfg.LogLevel = defaultLogLevel
+	}
+
+	if logCfg.ElasticCfg.Index == "" {
+		logCfg.ElasticCfg.Index = elastic_log.DefaultIndex
+	}
+	if logCfg.ElasticCfg.BatchSize == 0 {
+		logCfg.ElasticCfg.BatchSize = elastic_log.DefaultBatchSize
 	}
+	if logCfg.ElasticCfg.BatchWait == 0 {
+		logCfg.ElasticCfg.BatchWait = elastic_log.DefaultBatchWait
+	}
+	if logCfg.ElasticCfg.LogLevel == "" {
+		logCfg.ElasticCfg.LogLevel = defaultLogLevel
+	}
+
+	if logCfg.FluentCfg.Network == "" {
+		logCfg.FluentCfg.Network = "tcp"
+	}
+	if logCfg.FluentCfg.Address == "" {
+		logCfg.FluentCfg.Address = "localhost:24224"
+	}
+	if logCfg.FluentCfg.BatchSize == 0 {
+		logCfg.FluentCfg.BatchSize = fluent_log.DefaultBatchSize
+	}
+	if logCfg.FluentCfg.BatchWait == 0 {
+		logCfg.FluentCfg.BatchWait = fluent_log.DefaultBatchWait
+	}
+	if logCfg.FluentCfg.LogLevel == "" {
+		logCfg.FluentCfg.LogLevel = defaultLogLevel
+	}
+
+	if logCfg.Webhooks != nil {
+		logCfg.Webhooks = append([]WebhookCfg{}, logCfg.Webhooks...) // don't modify the caller's slice
+	}
+	for i := range logCfg.Webhooks {
+		wh := &logCfg.Webhooks[i]
+		if wh.LogLevel == "" {
+			wh.LogLevel = defaultWebhookLogLevel
+		}
+		wh.LogLevel = strings.ToLower(wh.LogLevel)
+	}
+
+	if logCfg.Outputs != nil {
+		logCfg.Outputs = append([]OutputCfg{}, logCfg.Outputs...) // don't modify the caller's slice
+	}
+	for i := range logCfg.Outputs {
+		out := &logCfg.Outputs[i]
+		out.LogLevel = strings.ToLower(out.LogLevel)
+		out.Formatter = strings.ToLower(out.Formatter)
+	}
+
+	return logCfg
 }
 
-// SetLogFormat sets the log format with "json" for JSON, otherwise text
+// hookSpecs lists the hooks which can be built from logCfg
+func hookSpecs(logCfg LogConfig) []hookSpec {
+	var fileErr error
+	if logCfg.FileLogCfg.Enabled {
+		fileErr = file_log.CheckPath(logCfg.FileLogCfg.Path)
+	}
+	var gelfErr error
+	if gc := logCfg.GELFCfg; gc.ChunkSize < gelf_log.MinChunkSize {
+		gelfErr = fmt.Errorf("chunk size %d is below the minimum of %d", gc.ChunkSize, gelf_log.MinChunkSize)
+	}
+
+	return append([]hookSpec{
+		// Teams Log
+		{
+			name:    "teams",
+			enabled: logCfg.TeamsLogCfg.Enabled,
+			cfg:     logCfg.TeamsLogCfg,
+			build: func() logrus.Hook {
+				// Pass config down to local package
+				teams_log.SetTeamsCfg(teams_log.TeamsCfg{
+					Enabled:     logCfg.TeamsLogCfg.Enabled,
+					LogEndpoint: logCfg.TeamsLogCfg.Endpoint,
+					LogLevel:    logCfg.TeamsLogCfg.LogLevel,
+				})
+
+				hook := teams_log.NewTeamsLogHook(logCfg.TeamsLogCfg.Endpoint,
+					teams_log.AllowedLevels(logrusLevels[strings.ToLower(logCfg.TeamsLogCfg.LogLevel)]))
+				hook.CardFormat = strings.ToLower(logCfg.TeamsLogCfg.CardFormat)
+				hook.PinnedFields = logCfg.TeamsLogCfg.PinnedFields
+				hook.ExcludedFields = logCfg.TeamsLogCfg.ExcludedFields
+				if logCfg.TeamsLogCfg.Timeout > 0 {
+					hook.Client.Timeout = logCfg.TeamsLogCfg.Timeout
+				}
+				if logCfg.TeamsLogCfg.MaxRetries != 0 {
+					hook.Backoff.MaxRetries = max(logCfg.TeamsLogCfg.MaxRetries, 0)
+				}
+				for _, a := range logCfg.TeamsLogCfg.Actions {
+					hook.Actions = append(hook.Actions, teams_log.Action{Title: a.Title, URL: a.URL})
+				}
+				return hook
+			},
+		},
+		// LogChan hook — sends text-formatted log lines to a caller-provided channel
+		{
+			name:    "log_chan",
+			enabled: logCfg.LogChanCfg.Enabled,
+			cfg:     logCfg.LogChanCfg,
+			build: func() logrus.Hook {
+				acceptedLevel := logrusLevels[strings.ToLower(logCfg.LogChanCfg.LogLevel)]
+				acceptedLevels := log_chan.AllowedLevels(acceptedLevel)
+
+				return log_chan.NewLogChanHook(logCfg.LogChanCfg.Ch, acceptedLevels)
+			},
+		},
+		// Slack API Log
+		{
+			name:    "slack_api",
+			enabled: logCfg.SlackAPICfg.Enabled,
+			cfg:     logCfg.SlackAPICfg,
+			build: func() logrus.Hook {
+				// Convert string log level to logrus level
+				acceptedLevel := logrusLevels[strings.ToLower(logCfg.SlackAPICfg.LogLevel)]
+				acceptedLevels := AllowedLevels(acceptedLevel)
+				fmt.Println("Slack API acceptedLevels:", acceptedLevels)
+
+				return slack_api.NewSlackAPIHook(
+					logCfg.SlackAPICfg.Token,
+					logCfg.SlackAPICfg.Channel,
+					acceptedLevels,
+					logCfg.SlackAPICfg.UseBlocks,
+				)
+			},
+		},
+		// File Log
+		{
+			name:    "file",
+			enabled: logCfg.FileLogCfg.Enabled,
+			cfg:     logCfg.FileLogCfg,
+			err:     fileErr,
+			build: func() logrus.Hook {
+				fc := logCfg.FileLogCfg
+				rf, err := file_log.NewRotatingFile(file_log.RotatingFileCfg{
+					Path:        fc.Path,
+					MaxSizeMB:   fc.MaxSizeMB,
+					Daily:       fc.Daily,
+					Compress:    fc.Compress,
+					MaxAge:      time.Duration(fc.MaxAgeDays) * 24 * time.Hour,
+					MaxBackups:  fc.MaxBackups,
+					ReopenOnHUP: fc.ReopenOnHUP,
+				})
+				if err != nil { // checked above, so the file system changed since
+					fmt.Println("Unable to set up file logging:", err)
+					return file_log.NewFileLogHook(io.Discard, outputFormatter(fileFormat(fc.Formatter)), []logrus.Level{})
+				}
+
+				acceptedLevels := AllowedLevels(logrusLevels[strings.ToLower(fc.LogLevel)])
+				return file_log.NewFileLogHook(rf, outputFormatter(fileFormat(fc.Formatter)), acceptedLevels)
+			},
+		},
+		// Syslog
+		{
+			name:    "syslog",
+			enabled: logCfg.SyslogCfg.Enabled,
+			cfg:     logCfg.SyslogCfg,
+			build: func() logrus.Hook {
+				sc := logCfg.SyslogCfg
+				facility, ok := syslog_log.Facilities[strings.ToLower(sc.Facility)]
+				if !ok {
+					fmt.Printf("Unknown syslog facility %q, using \"user\"\n", sc.Facility)
+					facility = syslog_log.Facilities["user"]
+				}
+
+				hook := syslog_log.NewSyslogHook(sc.Network, sc.Address, facility, sc.AppName,
+					AllowedLevels(logrusLevels[strings.ToLower(sc.LogLevel)]))
+				if sc.SDID != "" {
+					hook.SDID = sc.SDID
+				}
+				return hook
+			},
+		},
+		// systemd-journald
+		{
+			name:    "journald",
+			enabled: logCfg.JournaldCfg.Enabled,
+			cfg:     logCfg.JournaldCfg,
+			build: func() logrus.Hook {
+				jc := logCfg.JournaldCfg
+				return journald_log.NewJournaldHook(jc.SocketPath, jc.SyslogIdentifier,
+					AllowedLevels(logrusLevels[strings.ToLower(jc.LogLevel)]))
+			},
+		},
+		// Graylog (GELF)
+		{
+			name:    "gelf",
+			enabled: logCfg.GELFCfg.Enabled,
+			cfg:     logCfg.GELFCfg,
+			err:     gelfErr,
+			build: func() logrus.Hook {
+				gc := logCfg.GELFCfg
+				hook := gelf_log.NewGELFHook(gc.Network, gc.Address, AllowedLevels(logrusLevels[strings.ToLower(gc.LogLevel)]))
+				hook.Compression = strings.ToLower(gc.Compression)
+				hook.ChunkSize = gc.ChunkSize
+				if gc.Host != "" {
+					hook.Host = gc.Host
+				}
+				return hook
+			},
+		},
+		// Grafana Loki
+		{
+			name:    "loki",
+			enabled: logCfg.LokiCfg.Enabled,
+			cfg:     logCfg.LokiCfg,
+			build: func() logrus.Hook {
+				lc := logCfg.LokiCfg
+				hook := loki_log.NewLokiHook(lc.URL, lc.Labels, lc.LabelFields,
+					AllowedLevels(logrusLevels[strings.ToLower(lc.LogLevel)]))
+				hook.TenantID = lc.TenantID
+				hook.Username, hook.Password = lc.Username, lc.Password
+				hook.BatchSize, hook.BatchWait = lc.BatchSize, lc.BatchWait
+				return hook
+			},
+		},
+		// OpenTelemetry (OTLP/HTTP)
+		{
+			name:    "otlp",
+			enabled: logCfg.OTLPCfg.Enabled,
+			cfg:     logCfg.OTLPCfg,
+			build: func() logrus.Hook {
+				oc := logCfg.OTLPCfg
+				resourceAttrs := maps.Clone(oc.ResourceAttributes)
+				if oc.ServiceName != "" {
+					if resourceAttrs == nil {
+						resourceAttrs = map[string]string{}
+					}
+					resourceAttrs["service.name"] = oc.ServiceName
+				}
+
+				hook := otlp_log.NewOTLPHook(oc.Endpoint, oc.Protocol, resourceAttrs,
+					AllowedLevels(logrusLevels[strings.ToLower(oc.LogLevel)]))
+				hook.Headers = oc.Headers
+				hook.Gzip = oc.Gzip
+				hook.BatchSize, hook.BatchWait = oc.BatchSize, oc.BatchWait
+				return hook
+			},
+		},
+		// Elasticsearch / OpenSearch
+		{
+			name:    "elastic",
+			enabled: logCfg.ElasticCfg.Enabled,
+			cfg:     logCfg.ElasticCfg,
+			build: func() logrus.Hook {
+				ec := logCfg.ElasticCfg
+				hook := elastic_log.NewElasticHook(ec.URL, ec.Index, AllowedLevels(logrusLevels[strings.ToLower(ec.LogLevel)]))
+				hook.Username, hook.Password = ec.Username, ec.Password
+				hook.APIKey = ec.APIKey
+				hook.BatchSize, hook.BatchWait = ec.BatchSize, ec.BatchWait
+				return hook
+			},
+		},
+		// Fluentd / Fluent Bit (forward protocol)
+		{
+			name:    "fluent",
+			enabled: logCfg.FluentCfg.Enabled,
+			cfg:     logCfg.FluentCfg,
+			build: func() logrus.Hook {
+				fc := logCfg.FluentCfg
+				hook := fluent_log.NewFluentHook(fc.Network, fc.Address, fc.Tag,
+					AllowedLevels(logrusLevels[strings.ToLower(fc.LogLevel)]))
+				hook.RequireAck = fc.RequireAck
+				hook.BatchSize, hook.BatchWait = fc.BatchSize, fc.BatchWait
+				return hook
+			},
+		},
+	}, slices.Concat(webhookSpecs(logCfg.Webhooks), outputSpecs(logCfg.Outputs))...)
+}
+
+// webhookHookPrefix starts the managed hook names of webhooks, e.g. "webhook:incidents"
+const webhookHookPrefix = "webhook:"
+
+// webhookSpecs lists a hook for each configured webhook
+func webhookSpecs(webhooks []WebhookCfg) []hookSpec {
+	specs := make([]hookSpec, 0, len(webhooks))
+	for i, wc := range webhooks {
+		name := wc.Name
+		if name == "" {
+			name = strconv.Itoa(i + 1)
+		}
+		var err error
+		if wc.Template != "" {
+			_, err = webhook_log.ParseTemplate(wc.Template)
+		}
+		specs = append(specs, hookSpec{
+			name:    webhookHookPrefix + name,
+			enabled: true,
+			cfg:     wc,
+			err:     err,
+			build: func() logrus.Hook {
+				hook, _ := webhook_log.NewWebhookHook(wc.URL, wc.Template, AllowedLevels(logrusLevels[wc.LogLevel])) // err checked above
+				if wc.Method != "" {
+					hook.Method = strings.ToUpper(wc.Method)
+				}
+				if wc.ContentType != "" {
+					hook.ContentType = wc.ContentType
+				}
+				if wc.SignatureHeader != "" {
+					hook.SignatureHeader = wc.SignatureHeader
+				}
+				hook.Headers = wc.Headers
+				hook.Secret = wc.Secret
+				return hook
+			},
+		})
+	}
+	return specs
+}
+
+// fileFormat returns the format for file output: "json", otherwise "logfmt" (text without colors)
+func fileFormat(format string) string {
+	if format == "json" {
+		return format
+	}
+	return "logfmt"
+}
+
+func findManagedHook(hooks []managedHook, name string) (managedHook, bool) {
+	for _, mh := range hooks {
+		if mh.name == name {
+			return mh, true
+		}
+	}
+	return managedHook{}, false
+}
+
+func isManagedHook(hooks []managedHook, hook logrus.Hook) bool {
+	if g, ok := hook.(*gatedHook); ok {
+		hook = g.Hook
+	}
+	for _, mh := range hooks {
+		if sameHook(mh.hook, hook) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetLogFormat sets the log format of the default Logger with "json" for JSON, otherwise text
 func SetLogFormat(format string) {
+	defaultLogger.SetLogFormat(format)
+}
+
+// SetLogFormat sets the log format with "json" for JSON, otherwise text
+func (l *Logger) SetLogFormat(format string) {
+	l.cfgMu.Lock()
+	defer l.cfgMu.Unlock()
+
+	l.setLogFormat(format)
+	l.cfg.Formatter = withConfigDefaults(LogConfig{Formatter: format}).Formatter
+}
+
+func (l *Logger) setLogFormat(format string) {
 	format = strings.ToLower(format)
 	if format == "json" {
-		logrus.SetFormatter(&logrus.JSONFormatter{})
+		l.lr.SetFormatter(levelGate{&logrus.JSONFormatter{}, l})
 	} else {
-		logrus.SetFormatter(&logrus.TextFormatter{})
+		l.lr.SetFormatter(levelGate{&logrus.TextFormatter{}, l})
 	}
 }
 
-// SetLogLevel sets the log level, defaulting to info
-// logLevel can be "debug | info | warn | error"
+// SetLogLevel sets the log level of the default Logger, defaulting to info
+// logLevel can be "trace | debug | info | warn | error | fatal | panic"
 func SetLogLevel(logLevel string) {
-	logLevel = strings.ToLower(logLevel)
+	defaultLogger.SetLogLevel(logLevel)
+}
+
+// SetLogLevel sets the log level, defaulting to info
+// logLevel can be "trace | debug | info | warn | error | fatal | panic"
+func (l *Logger) SetLogLevel(logLevel string) {
+	l.cfgMu.Lock()
+	defer l.cfgMu.Unlock()
+
+	l.setLogLevel(logLevel)
+	l.cfg.LogLevel = strings.ToLower(logLevel)
+}
+
+// GetLogLevel returns the level of the default Logger
+func GetLogLevel() string {
+	return defaultLogger.GetLogLevel()
+}
+
+// GetLogLevel returns the log level. For named Loggers see ComponentLevels
+func (l *Logger) GetLogLevel() string {
+	if lr := l.levels.Load(); lr != nil {
+		return lr.base.String()
+	}
+	return l.lr.GetLevel().String()
+}
 
-	if logLevel == "warning" {
-		logLevel = "warn"
+// setLogLevel sets the base level, keeping the levels of named Loggers
+func (l *Logger) setLogLevel(logLevel string) {
+	var rules map[string]logrus.Level
+	if lr := l.levels.Load(); lr != nil {
+		rules = lr.rules
 	}
+	l.setLevels(logLevel, rules, l.cfg.Outputs)
+}
+
+// setLevels sets the base level and the levels of named Loggers.
+// The logrus instance is set to the most verbose of them and of the outputs, finer filtering is done per Logger
+func (l *Logger) setLevels(logLevel string, rules map[string]logrus.Level, outputs []OutputCfg) {
+	logLevel = strings.ToLower(logLevel)
 
 	logrusLevel := logrus.InfoLevel
 
@@ -125,5 +733,20 @@ func SetLogLevel(logLevel string) {
 		logrusLevel = ll
 	}
 
-	logrus.SetLevel(logrusLevel)
+	var outputsLevel logrus.Level // an output more verbose than the base level must still receive its entries
+	for _, out := range outputs {
+		if ll, ok := logrusLevels[strings.ToLower(out.LogLevel)]; ok {
+			outputsLevel = max(outputsLevel, ll)
+		}
+	}
+
+	if len(rules) == 0 && outputsLevel <= logrusLevel {
+		l.levels.Store(nil)
+		l.lr.SetLevel(logrusLevel)
+		return
+	}
+
+	lr := &levelRules{base: logrusLevel, outputs: outputsLevel, rules: rules}
+	l.levels.Store(lr)
+	l.lr.SetLevel(lr.mostVerbose())
 }
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestReconfigureDoesNotDuplicateHooks(t *testing.T) {
+	logCh := make(chan string, 10)
+	cfg := LogConfig{
+		Formatter:  "text",
+		LogLevel:   "debug",
+		LogChanCfg: LogChanCfg{Enabled: true, Ch: logCh, LogLevel: "warn"},
+	}
+
+	lgr := New(cfg)
+	defer lgr.Close()
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+
+	external := &deliveryHook{}
+	lgr.Logrus().AddHook(external)
+
+	if changes := lgr.Reconfigure(cfg); len(changes) != 0 {
+		t.Errorf("expected no changes for the same config, got %v", changes)
+	}
+
+	lgr.Warn("Disk usage high")
+	if n := len(logCh); n != 1 {
+		t.Fatalf("expected the LogChan hook to fire once, it fired %d times", n)
+	}
+	<-logCh
+
+	firstQueue := lgr.async.Load()
+
+	cfg.Formatter = "json"
+	cfg.LogLevel = "info"
+	cfg.AsyncPolicy = AsyncPolicyDropOldest
+	cfg.LogChanCfg.LogLevel = "error"
+	changes := lgr.Reconfigure(cfg)
+
+	expected := []string{
+		`formatter: "text" -> "json"`,
+		`level: "debug" -> "info"`,
+		"hook replaced: log_chan",
+		"async queue: 2000/block -> 2000/drop-oldest",
+	}
+	for _, exp := range expected {
+		if !slices.Contains(changes, exp) {
+			t.Errorf("expected change %q in %v", exp, changes)
+		}
+	}
+
+	if !firstQueue.isClosed() {
+		t.Error("expected the previous async queue to be shut down")
+	}
+
+	lgr.Warn("Below the new LogChan level")
+	lgr.Error("Database unreachable")
+	if n := len(logCh); n != 1 {
+		t.Errorf("expected only the error to reach the LogChan hook, got %d messages", n)
+	}
+
+	if n := len(uniqueHooks(lgr.Logrus().Hooks)); n != 2 {
+		t.Errorf("expected the LogChan hook and the external hook, got %d hooks", n)
+	}
+
+	cfg.LogChanCfg.Enabled = false
+	if changes := lgr.Reconfigure(cfg); !slices.Contains(changes, "hook removed: log_chan") {
+		t.Errorf("expected the LogChan hook to be removed, got %v", changes)
+	}
+	if hooks := uniqueHooks(lgr.Logrus().Hooks); len(hooks) != 1 || hooks[0] != external {
+		t.Errorf("expected only the external hook to remain, got %v", hooks)
+	}
+}
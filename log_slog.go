@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+
+	"github.com/rohanthewiz/serr"
+	"github.com/sirupsen/logrus"
+)
+
+// SlogOpts are optional settings for the slog handler
+type SlogOpts struct {
+	AddSource bool // add a "location" field (file:line) of the slog call site
+}
+
+// slogHandler is a slog.Handler that feeds slog records into the same pipeline as Log(),
+// so slog output goes through the Logger's level, formatter, prefix and hooks
+type slogHandler struct {
+	l      *Logger
+	opts   SlogOpts
+	attrs  []slog.Attr // attributes added via WithAttrs, already qualified by their groups
+	groups []string    // open groups, used to qualify attribute keys
+}
+
+// SlogHandler returns a slog.Handler backed by the default Logger
+// Example:
+//
+//	slog.SetDefault(slog.New(logger.SlogHandler()))
+//	slog.Info("Payment processed", "service", "payment-gateway", "amount", 99.99)
+func SlogHandler(options ...SlogOpts) slog.Handler {
+	return defaultLogger.SlogHandler(options...)
+}
+
+// SlogHandler returns a slog.Handler backed by this Logger
+func (l *Logger) SlogHandler(options ...SlogOpts) slog.Handler {
+	var opts SlogOpts
+	if len(options) > 0 {
+		opts = options[0]
+	}
+	return &slogHandler{l: l, opts: opts}
+}
+
+// Enabled reports whether the Logger's level lets records of level through
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.l.levelEnabled(logrusLevelFromSlog(level))
+}
+
+// Handle converts the record's attributes into logrus fields and logs it
+func (h *slogHandler) Handle(_ context.Context, rec slog.Record) error {
+	flds := logrus.Fields{}
+
+	for _, attr := range h.attrs {
+		addSlogAttr(flds, "", attr)
+	}
+
+	prefix := strings.Join(h.groups, ".")
+	rec.Attrs(func(attr slog.Attr) bool {
+		addSlogAttr(flds, prefix, attr)
+		return true
+	})
+
+	if h.opts.AddSource && rec.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{rec.PC}).Next()
+		flds["location"] = fmt.Sprintf("%s:%d", serr.LastNTokens(frame.File, "/", 2), frame.Line)
+	}
+
+	h.l.logEntry(logrusLevelFromSlog(rec.Level), rec.Message, flds, rec.Time)
+	return nil
+}
+
+// WithAttrs returns a handler that includes attrs in every record
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	h2 := *h
+	h2.attrs = make([]slog.Attr, 0, len(h.attrs)+len(attrs))
+	h2.attrs = append(h2.attrs, h.attrs...)
+
+	prefix := strings.Join(h.groups, ".")
+	for _, attr := range attrs {
+		if prefix != "" {
+			attr.Key = prefix + "." + attr.Key
+		}
+		h2.attrs = append(h2.attrs, attr)
+	}
+	return &h2
+}
+
+// WithGroup returns a handler that qualifies subsequent attribute keys with name
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := *h
+	h2.groups = append(append([]string{}, h.groups...), name)
+	return &h2
+}
+
+// addSlogAttr adds attr to flds, flattening groups into dotted keys
+func addSlogAttr(flds logrus.Fields, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return // slog says to ignore empty attributes
+	}
+
+	key := attr.Key
+	if prefix != "" && key != "" {
+		key = prefix + "." + key
+	} else if key == "" {
+		key = prefix // an inline group (empty key) lifts its attributes to the current level
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		for _, ga := range attr.Value.Group() {
+			addSlogAttr(flds, key, ga)
+		}
+		return
+	}
+
+	flds[key] = fieldValue(attr.Value.Any())
+}
+
+// logrusLevelFromSlog maps slog levels onto logrus levels.
+// Levels between the standard slog levels round down to the more verbose logrus level
+func logrusLevelFromSlog(level slog.Level) logrus.Level {
+	switch {
+	case level >= slog.LevelError:
+		return logrus.ErrorLevel
+	case level >= slog.LevelWarn:
+		return logrus.WarnLevel
+	case level >= slog.LevelInfo:
+		return logrus.InfoLevel
+	case level >= slog.LevelDebug:
+		return logrus.DebugLevel
+	default:
+		return logrus.TraceLevel
+	}
+}
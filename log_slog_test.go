@@ -0,0 +1,67 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+	logCh := make(chan string, 10)
+
+	lgr := New(LogConfig{
+		Formatter: "json",
+		LogLevel:  "info",
+		LogChanCfg: LogChanCfg{
+			Enabled:  true,
+			Ch:       logCh,
+			LogLevel: "warn",
+		},
+	})
+	defer lgr.Close()
+
+	var out bytes.Buffer
+	lgr.Logrus().SetOutput(&out)
+
+	slgr := slog.New(lgr.SlogHandler(SlogOpts{AddSource: true}))
+	slgr.Debug("Filtered out by level")
+	slgr.With("service", "payment-gateway").WithGroup("req").
+		Warn("Slow response", "latency_ms", 1500, slog.Group("client", "ip", "192.168.1.100"))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected a single log line, got %d: %s", len(lines), out.String())
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("unable to unmarshal log line: %v", err)
+	}
+
+	expected := map[string]any{
+		"msg":            "Slow response",
+		"level":          "warning",
+		"service":        "payment-gateway",
+		"req.latency_ms": float64(1500),
+		"req.client.ip":  "192.168.1.100",
+	}
+	for key, val := range expected {
+		if entry[key] != val {
+			t.Errorf("field %q = %v, expected %v", key, entry[key], val)
+		}
+	}
+	if loc, _ := entry["location"].(string); !strings.Contains(loc, "log_slog_test.go:") {
+		t.Errorf("expected location of the slog call, got %q", loc)
+	}
+
+	select {
+	case msg := <-logCh:
+		if !strings.Contains(msg, "Slow response") {
+			t.Errorf("unexpected hook message: %s", msg)
+		}
+	default:
+		t.Error("expected the slog record to reach the LogChan hook")
+	}
+}
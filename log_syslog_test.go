@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rohanthewiz/logger/hooks/syslog_log"
+	"github.com/sirupsen/logrus"
+)
+
+func TestSyslogUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	lgr := New(LogConfig{
+		LogLevel: "debug",
+		SyslogCfg: SyslogCfg{
+			Enabled: true, Address: pc.LocalAddr().String(),
+			Facility: "local3", AppName: "billing", LogLevel: "info",
+		},
+	})
+	defer lgr.Close()
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+
+	lgr.Debug("Below the syslog level")
+	lgr.Warn("Card expiring", "customer", `Jane "JD" Doe`, "days", 3)
+
+	msg := readPacket(t, pc)
+
+	// local3 (19) * 8 + warning (4) = 156
+	header := regexp.MustCompile(`^<156>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}\S+ \S+ billing \d+ - `)
+	if !header.MatchString(msg) {
+		t.Errorf("unexpected header in %q", msg)
+	}
+	if !strings.HasSuffix(msg, ` [fields@32473 customer="Jane \"JD\" Doe" days="3"] Card expiring`) {
+		t.Errorf("unexpected structured data or message in %q", msg)
+	}
+}
+
+func TestSyslogTCPOctetCounting(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	hook := syslog_log.NewSyslogHook("tcp", ln.Addr().String(), syslog_log.Facilities["daemon"], "api", logrus.AllLevels)
+	defer hook.Close()
+
+	lgr := New(LogConfig{LogLevel: "debug"})
+	defer lgr.Close()
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+	lgr.Logrus().AddHook(hook)
+
+	lgr.Error("Upstream timeout\nretrying", "upstream", "payments")
+	lgr.Info("Recovered")
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	rd := bufio.NewReader(conn)
+
+	first := readFrame(t, rd)
+	if !strings.HasPrefix(first, "<27>1 ") || !strings.HasSuffix(first, "] Upstream timeout\nretrying") {
+		t.Errorf("unexpected first message %q", first)
+	}
+	if second := readFrame(t, rd); !strings.HasPrefix(second, "<30>1 ") || !strings.HasSuffix(second, " - - Recovered") {
+		t.Errorf("unexpected second message %q", second)
+	}
+}
+
+func TestSyslogUnixReconnect(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "syslog.sock")
+
+	hook := syslog_log.NewSyslogHook("unix", sock, syslog_log.Facilities["user"], "worker", logrus.AllLevels)
+	hook.RetryInterval = 10 * time.Millisecond
+	defer hook.Close()
+
+	entry := &logrus.Entry{Logger: logrus.New(), Time: time.Now(), Level: logrus.InfoLevel, Data: logrus.Fields{}}
+
+	entry.Message = "Before syslog is up"
+	_ = hook.Fire(entry) // dropped, nothing is listening
+	if err := hook.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	pc, err := net.ListenPacket("unixgram", sock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	time.Sleep(20 * time.Millisecond) // past the retry interval
+	entry.Message = "After syslog is up"
+	_ = hook.Fire(entry)
+
+	if msg := readPacket(t, pc); !strings.HasSuffix(msg, " - - After syslog is up") {
+		t.Errorf("expected the hook to reconnect, got %q", msg)
+	}
+}
+
+func readPacket(t *testing.T, pc net.PacketConn) string {
+	t.Helper()
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 4096)
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(buf[:n])
+}
+
+// readFrame reads an octet-counted message: "LEN SP MSG"
+func readFrame(t *testing.T, rd *bufio.Reader) string {
+	t.Helper()
+	lenStr, err := rd.ReadString(' ')
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(lenStr))
+	if err != nil {
+		t.Fatalf("invalid frame length %q", lenStr)
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(rd, buf); err != nil {
+		t.Fatal(err)
+	}
+	return string(buf)
+}
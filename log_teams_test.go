@@ -0,0 +1,307 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rohanthewiz/logger/teams_log"
+	"github.com/sirupsen/logrus"
+)
+
+// teamsStandIn records the messages posted to it, answering like a Workflows webhook.
+// It throttles the first throttled posts with 429, and holds posts while hold is open
+type teamsStandIn struct {
+	mu        sync.Mutex
+	messages  []map[string]any
+	throttled int
+	hold      chan struct{}
+}
+
+func (ts *teamsStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if ts.hold != nil {
+		<-ts.hold
+	}
+
+	var msg map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if ts.throttled > 0 {
+		ts.throttled--
+		w.Header().Set("Retry-After", "0")
+		http.Error(w, "too many requests", http.StatusTooManyRequests)
+		return
+	}
+	ts.messages = append(ts.messages, msg)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func TestTeamsAdaptiveCard(t *testing.T) {
+	teams := &teamsStandIn{}
+	srv := httptest.NewServer(teams)
+	defer srv.Close()
+
+	lgr := New(LogConfig{
+		TeamsLogCfg: TeamsLogCfg{
+			Enabled: true, Endpoint: srv.URL, LogLevel: "error", CardFormat: "adaptive_card",
+			Actions: []TeamsAction{{Title: "Runbook", URL: "https://wiki.example.com/payments"}},
+		},
+	})
+	defer lgr.Close()
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+
+	lgr.Err(errors.New("card expired"), "Payment declined", "order_id", "A-1002")
+	if err := lgr.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	teams.mu.Lock()
+	defer teams.mu.Unlock()
+
+	if len(teams.messages) != 1 {
+		t.Fatalf("expected one message, got %d", len(teams.messages))
+	}
+	var msg teams_log.WorkflowMessage
+	raw, _ := json.Marshal(teams.messages[0])
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		t.Fatal(err)
+	}
+
+	if msg.Type != "message" || len(msg.Attachments) != 1 ||
+		msg.Attachments[0].ContentType != "application/vnd.microsoft.card.adaptive" {
+		t.Fatalf("expected a Workflows message with an adaptive card, got %s", raw)
+	}
+	card := msg.Attachments[0].Content
+	if card.Type != "AdaptiveCard" || len(card.Body) < 3 {
+		t.Fatalf("unexpected card %s", raw)
+	}
+
+	header := card.Body[0]
+	if header.Style != "attention" || header.Items[0].Text != "Payment declined" || header.Items[0].Color != "Attention" {
+		t.Errorf("expected an accented header with the message, got %+v", header)
+	}
+
+	var facts map[string]string
+	var errBlock *teams_log.CardElement
+	for i, el := range card.Body {
+		switch {
+		case el.Type == "FactSet":
+			facts = map[string]string{}
+			for _, f := range el.Facts {
+				facts[f.Title] = f.Value
+			}
+		case el.FontType == "Monospace":
+			errBlock = &card.Body[i]
+		}
+	}
+	if facts["order_id"] != "A-1002" {
+		t.Errorf("expected the fields as facts, got %v", facts)
+	}
+	if errBlock == nil || errBlock.Text == "" {
+		t.Errorf("expected a monospace block with the error, got %s", raw)
+	}
+
+	if len(card.Actions) != 1 || card.Actions[0].Type != "Action.OpenUrl" || card.Actions[0].URL != "https://wiki.example.com/payments" {
+		t.Errorf("expected the runbook button, got %+v", card.Actions)
+	}
+}
+
+func TestTeamsDoesNotBlockLogging(t *testing.T) {
+	teams := &teamsStandIn{hold: make(chan struct{})}
+	srv := httptest.NewServer(teams)
+	defer srv.Close()
+
+	lgr := New(LogConfig{TeamsLogCfg: TeamsLogCfg{Enabled: true, Endpoint: srv.URL}})
+	defer lgr.Close()
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+
+	logged := make(chan struct{})
+	go func() {
+		lgr.Error("Payment declined")
+		lgr.Warn("Retrying payment")
+		close(logged)
+	}()
+
+	select {
+	case <-logged:
+	case <-time.After(2 * time.Second):
+		t.Fatal("logging blocked on the Teams endpoint")
+	}
+
+	close(teams.hold)
+	if err := lgr.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	teams.mu.Lock()
+	defer teams.mu.Unlock()
+	if len(teams.messages) != 2 {
+		t.Errorf("expected both messages once the endpoint answered, got %d", len(teams.messages))
+	}
+}
+
+func TestTeamsRetriesWhenThrottled(t *testing.T) {
+	teams := &teamsStandIn{throttled: 2}
+	srv := httptest.NewServer(teams)
+	defer srv.Close()
+
+	hook := teams_log.NewTeamsLogHook(srv.URL, logrus.AllLevels)
+	hook.Backoff.Min = 5 * time.Millisecond
+
+	lgr := logrus.New()
+	lgr.SetOutput(&bytes.Buffer{})
+	lgr.AddHook(hook)
+	lgr.Error("Payment declined")
+
+	if err := hook.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	teams.mu.Lock()
+	defer teams.mu.Unlock()
+	if teams.throttled != 0 || len(teams.messages) != 1 {
+		t.Errorf("expected the message to be delivered after the 429s, got %d", len(teams.messages))
+	}
+}
+
+func TestTeamsCloseGivesUpOnHungEndpoint(t *testing.T) {
+	teams := &teamsStandIn{hold: make(chan struct{})}
+	srv := httptest.NewServer(teams)
+	defer srv.Close()
+
+	hook := teams_log.NewTeamsLogHook(srv.URL, logrus.AllLevels)
+
+	lgr := logrus.New()
+	lgr.SetOutput(&bytes.Buffer{})
+	lgr.AddHook(hook)
+	lgr.Error("Payment declined")
+	lgr.Error("Refund failed")
+	lgr.Error("Invoice rejected")
+
+	start := time.Now()
+	if err := hook.Close(); err == nil {
+		t.Error("expected Close to report the cards it gave up on")
+	}
+	if elapsed := time.Since(start); elapsed > 7*time.Second {
+		t.Errorf("expected Close to give up after its timeout, took %s", elapsed)
+	}
+
+	// The card in flight gets through once the endpoint answers, the queued ones are dropped
+	close(teams.hold)
+	time.Sleep(200 * time.Millisecond)
+
+	teams.mu.Lock()
+	defer teams.mu.Unlock()
+	if len(teams.messages) > 1 {
+		t.Errorf("expected the queued cards to be dropped, got %d messages", len(teams.messages))
+	}
+}
+
+func TestTeamsDefaultsBackoff(t *testing.T) {
+	teams := &teamsStandIn{throttled: 1}
+	srv := httptest.NewServer(teams)
+	defer srv.Close()
+
+	hook := &teams_log.TeamsLogHook{URL: srv.URL, AcceptedLevels: logrus.AllLevels}
+
+	lgr := logrus.New()
+	lgr.SetOutput(&bytes.Buffer{})
+	lgr.AddHook(hook)
+	lgr.Error("Payment declined")
+
+	if err := hook.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	teams.mu.Lock()
+	defer teams.mu.Unlock()
+	if len(teams.messages) != 1 {
+		t.Errorf("expected a hook built without NewTeamsLogHook to retry the 429, got %d messages", len(teams.messages))
+	}
+}
+
+func TestTeamsRendersAllFieldTypes(t *testing.T) {
+	teams := &teamsStandIn{}
+	srv := httptest.NewServer(teams)
+	defer srv.Close()
+
+	lgr := New(LogConfig{
+		TeamsLogCfg: TeamsLogCfg{
+			Enabled: true, Endpoint: srv.URL, LogLevel: "warn", CardFormat: "adaptive_card",
+			PinnedFields:   []string{"service", "attempts"},
+			ExcludedFields: []string{"secret"},
+		},
+	})
+	defer lgr.Close()
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+
+	lgr.Warn("Payment retried",
+		"attempts", 3,
+		"latency", 1500*time.Millisecond,
+		"cause", errors.New("gateway timeout"),
+		"card", map[string]any{"brand": "visa", "last4": "4242"},
+		"amounts", []float64{9.99, 19.99},
+		"service", "payments",
+		"secret", "s3cr3t",
+	)
+	if err := lgr.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	teams.mu.Lock()
+	defer teams.mu.Unlock()
+	if len(teams.messages) != 1 {
+		t.Fatalf("expected one message, got %d", len(teams.messages))
+	}
+	var msg teams_log.WorkflowMessage
+	raw, _ := json.Marshal(teams.messages[0])
+	if err := json.Unmarshal(raw, &msg); err != nil {
+		t.Fatal(err)
+	}
+
+	var facts []teams_log.CardFact
+	for _, el := range msg.Attachments[0].Content.Body {
+		if el.Type == "FactSet" {
+			facts = el.Facts
+		}
+	}
+
+	want := []teams_log.CardFact{
+		{Title: "service", Value: "payments"},
+		{Title: "attempts", Value: "3"},
+		{Title: "amounts", Value: "[9.99,19.99]"},
+		{Title: "card", Value: `{"brand":"visa","last4":"4242"}`},
+		{Title: "cause", Value: "gateway timeout"},
+		{Title: "latency", Value: "1.5s"},
+	}
+	if len(facts) != len(want) {
+		t.Fatalf("expected facts %v, got %v", want, facts)
+	}
+	for i := range want {
+		if facts[i] != want[i] {
+			t.Errorf("fact %d: expected %v, got %v", i, want[i], facts[i])
+		}
+	}
+}
+
+func TestTeamsFormatsNilPointers(t *testing.T) {
+	for _, v := range []any{(*os.PathError)(nil), (*time.Time)(nil), (*int)(nil)} {
+		if got := teams_log.FormatValue(v); got != "<nil>" {
+			t.Errorf("expected <nil> for %T, got %q", v, got)
+		}
+	}
+	if got := teams_log.FormatValue(&os.PathError{Op: "open", Path: "/tmp/x", Err: os.ErrNotExist}); got != "open /tmp/x: file does not exist" {
+		t.Errorf("unexpected error text %q", got)
+	}
+}
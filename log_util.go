@@ -1,18 +1,23 @@
 package logger
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"path/filepath"
+	"reflect"
 	"runtime/debug"
 	"strings"
+	"time"
 )
 
-func strArrayFromAnyArgs(args ...any) (strArr []string) {
-	// fmt.Println("**-> len(args)", len(args))
+// anyArgsFromStrArgs converts string args to the []any taken by the typed core functions
+func anyArgsFromStrArgs(args []string) []any {
+	anyArgs := make([]any, 0, len(args))
 	for _, arg := range args {
-		strArr = append(strArr, fmt.Sprintf("%v", arg))
+		anyArgs = append(anyArgs, arg)
 	}
-	return
+	return anyArgs
 }
 
 type PrintStackTraceOpts struct {
@@ -46,3 +51,53 @@ func PrintStackTrace(options ...PrintStackTraceOpts) {
 		fmt.Println(strings.Repeat("-", 60))
 	}
 }
+
+// fieldValue prepares a value for use in logrus.Fields, preserving its type where the formatters can render it.
+// Times become RFC3339 strings, durations and errors their string forms,
+// and values that cannot be marshalled to JSON (NaN and ±Inf, funcs, chans, complex numbers,
+// and composites containing them) are stringified
+func fieldValue(val any) any {
+	switch v := val.(type) {
+	case nil, string, bool,
+		int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64:
+		return v
+	case float32:
+		return finiteOrString(float64(v), v)
+	case float64:
+		return finiteOrString(v, v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case time.Duration:
+		return v.String()
+	case error:
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+			return nil
+		}
+		return v.Error()
+	case []byte:
+		return string(v)
+	}
+
+	switch reflect.ValueOf(val).Kind() {
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Complex64, reflect.Complex128:
+		return fmt.Sprintf("%v", val)
+	case reflect.Float32, reflect.Float64: // named float types
+		return finiteOrString(reflect.ValueOf(val).Float(), val)
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct, reflect.Pointer, reflect.Interface:
+		// A value the JSON formatter can't marshal would make it drop the whole entry
+		if _, err := json.Marshal(val); err != nil {
+			return fmt.Sprintf("%v", val)
+		}
+	}
+	return val
+}
+
+// finiteOrString returns val, or its string form ("NaN", "+Inf", "-Inf") if f isn't finite,
+// as JSON has no representation for those
+func finiteOrString(f float64, val any) any {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Sprintf("%v", val)
+	}
+	return val
+}
@@ -0,0 +1,146 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rohanthewiz/logger/hooks/webhook_log"
+	"github.com/sirupsen/logrus"
+)
+
+type webhookRequest struct {
+	method string
+	header http.Header
+	body   []byte
+}
+
+// webhookStandIn records the requests it receives, failing the first failures of them with 503
+type webhookStandIn struct {
+	mu       sync.Mutex
+	requests []webhookRequest
+	failures int
+}
+
+func (ws *webhookStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if ws.failures > 0 {
+		ws.failures--
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	ws.requests = append(ws.requests, webhookRequest{method: r.Method, header: r.Header, body: body})
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func TestWebhook(t *testing.T) {
+	wh := &webhookStandIn{}
+	srv := httptest.NewServer(wh)
+	defer srv.Close()
+
+	lgr := New(LogConfig{
+		LogLevel: "debug",
+		Webhooks: []WebhookCfg{{
+			Name:     "incidents",
+			URL:      srv.URL,
+			Template: `{"text": {{printf "%s: %s (order %v)" (upper .Level) .Message (index .Fields "order_id") | json}}}`,
+			Headers:  map[string]string{"Authorization": "Bearer t0k3n"},
+			Secret:   "s3cr3t",
+			LogLevel: "error",
+		}},
+	})
+	defer lgr.Close()
+	lgr.Logrus().SetOutput(&bytes.Buffer{})
+
+	lgr.Info("Order shipped", "order_id", "A-1001")
+	lgr.Error("Payment declined", "order_id", "A-1002")
+
+	if err := lgr.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+
+	if len(wh.requests) != 1 {
+		t.Fatalf("expected only the error to be sent, got %d requests", len(wh.requests))
+	}
+	req := wh.requests[0]
+	if want := `{"text": "ERROR: Payment declined (order A-1002)"}`; string(req.body) != want {
+		t.Errorf("expected body %s, got %s", want, req.body)
+	}
+	if req.method != http.MethodPost || req.header.Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected method %s or content type %q", req.method, req.header.Get("Content-Type"))
+	}
+	if req.header.Get("Authorization") != "Bearer t0k3n" {
+		t.Errorf("expected the custom header, got %v", req.header)
+	}
+	if sig := req.header.Get(webhook_log.DefaultSignatureHeader); sig != webhook_log.Sign("s3cr3t", req.body) {
+		t.Errorf("expected the HMAC signature of the body, got %q", sig)
+	}
+}
+
+func TestWebhookBadTemplateDisablesIt(t *testing.T) {
+	wh := &webhookStandIn{}
+	srv := httptest.NewServer(wh)
+	defer srv.Close()
+
+	lgr := New(LogConfig{})
+	defer lgr.Close()
+	var out bytes.Buffer
+	lgr.Logrus().SetOutput(&out)
+
+	changes := lgr.Reconfigure(LogConfig{Webhooks: []WebhookCfg{{URL: srv.URL, Template: "{{.Message"}}})
+	if len(changes) != 1 || !strings.HasPrefix(changes[0], "hook disabled: webhook:1: webhook_log: invalid body template") {
+		t.Errorf("expected the webhook to be reported disabled, got %v", changes)
+	}
+	if !strings.Contains(out.String(), "level=error") || !strings.Contains(out.String(), "unclosed action") {
+		t.Errorf("expected the template error to be logged, got %s", out.String())
+	}
+
+	lgr.Error("Payment declined")
+	if err := lgr.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+	if len(wh.requests) != 0 {
+		t.Errorf("expected no requests from a webhook with a broken template, got %d", len(wh.requests))
+	}
+}
+
+func TestWebhookRetriesUnavailable(t *testing.T) {
+	wh := &webhookStandIn{failures: 2}
+	srv := httptest.NewServer(wh)
+	defer srv.Close()
+
+	hook, err := webhook_log.NewWebhookHook(srv.URL, "", logrus.AllLevels)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hook.Backoff.Min = 5 * time.Millisecond
+
+	lgr := logrus.New()
+	lgr.SetOutput(&bytes.Buffer{})
+	lgr.AddHook(hook)
+	lgr.Error("Payment declined")
+
+	if err := hook.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	wh.mu.Lock()
+	defer wh.mu.Unlock()
+	if wh.failures != 0 || len(wh.requests) != 1 {
+		t.Errorf("expected the entry to be delivered after the 503s, got %d requests", len(wh.requests))
+	}
+}
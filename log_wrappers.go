@@ -5,45 +5,116 @@ import (
 )
 
 func Warn(msg string, args ...any) {
-	Log(StrLevelWarn, msg, strArrayFromAnyArgs(args...)...)
+	defaultLogger.logKeyVals(StrLevelWarn, msg, args)
 }
 
 func Info(msg string, args ...any) {
-	Log(StrLevelInfo, msg, strArrayFromAnyArgs(args...)...)
+	defaultLogger.logKeyVals(StrLevelInfo, msg, args)
 }
 
 func Debug(msg string, args ...any) {
-	Log(StrLevelDebug, msg, strArrayFromAnyArgs(args...)...)
+	defaultLogger.logKeyVals(StrLevelDebug, msg, args)
+}
+
+func Trace(msg string, args ...any) {
+	defaultLogger.logKeyVals(StrLevelTrace, msg, args)
 }
 
 // Error will create a new error based on msg.
 // It is better to use LogErr(err, ...) if you are logging an existing error,
 // as that will include any error stack trace from serr
 func Error(msg string, args ...any) {
-	Log(StrLevelError, msg, strArrayFromAnyArgs(args...)...)
+	defaultLogger.logKeyVals(StrLevelError, msg, args)
+}
+
+// Panic logs at panic level, then panics
+func Panic(msg string, args ...any) {
+	defaultLogger.logKeyVals(StrLevelPanic, msg, args)
 }
 
 // F is a convenience function for log Info using a formatted string
 func F(format string, args ...any) {
-	Log(StrLevelInfo, fmt.Sprintf(format, args...))
+	defaultLogger.Log(StrLevelInfo, fmt.Sprintf(format, args...))
 }
 
 // InfoF is a convenience function for log Info using a formatted string
 func InfoF(format string, args ...any) {
-	Log(StrLevelInfo, fmt.Sprintf(format, args...))
+	defaultLogger.Log(StrLevelInfo, fmt.Sprintf(format, args...))
 }
 
 // DebugF is a convenience function for log Debug using a formatted string
 func DebugF(format string, args ...any) {
-	Log(StrLevelDebug, fmt.Sprintf(format, args...))
+	defaultLogger.Log(StrLevelDebug, fmt.Sprintf(format, args...))
+}
+
+// TraceF is a convenience function for log Trace using a formatted string
+func TraceF(format string, args ...any) {
+	defaultLogger.Log(StrLevelTrace, fmt.Sprintf(format, args...))
 }
 
 // WarnF is a convenience function for log Warn using a formatted string
 func WarnF(format string, args ...any) {
-	Log(StrLevelWarn, fmt.Sprintf(format, args...))
+	defaultLogger.Log(StrLevelWarn, fmt.Sprintf(format, args...))
 }
 
 // ErrorF is a convenience function for log Error using a formatted string
 func ErrorF(format string, args ...any) {
-	Log(StrLevelError, fmt.Sprintf(format, args...))
+	defaultLogger.Log(StrLevelError, fmt.Sprintf(format, args...))
+}
+
+func (l *Logger) Warn(msg string, args ...any) {
+	l.logKeyVals(StrLevelWarn, msg, args)
+}
+
+func (l *Logger) Info(msg string, args ...any) {
+	l.logKeyVals(StrLevelInfo, msg, args)
+}
+
+func (l *Logger) Debug(msg string, args ...any) {
+	l.logKeyVals(StrLevelDebug, msg, args)
+}
+
+func (l *Logger) Trace(msg string, args ...any) {
+	l.logKeyVals(StrLevelTrace, msg, args)
+}
+
+// Error will create a new error based on msg.
+// It is better to use LogErr(err, ...) if you are logging an existing error
+func (l *Logger) Error(msg string, args ...any) {
+	l.logKeyVals(StrLevelError, msg, args)
+}
+
+// Panic logs at panic level, then panics
+func (l *Logger) Panic(msg string, args ...any) {
+	l.logKeyVals(StrLevelPanic, msg, args)
+}
+
+// F is a convenience method for log Info using a formatted string
+func (l *Logger) F(format string, args ...any) {
+	l.Log(StrLevelInfo, fmt.Sprintf(format, args...))
+}
+
+// InfoF is a convenience method for log Info using a formatted string
+func (l *Logger) InfoF(format string, args ...any) {
+	l.Log(StrLevelInfo, fmt.Sprintf(format, args...))
+}
+
+// DebugF is a convenience method for log Debug using a formatted string
+func (l *Logger) DebugF(format string, args ...any) {
+	l.Log(StrLevelDebug, fmt.Sprintf(format, args...))
+}
+
+// TraceF is a convenience method for log Trace using a formatted string
+func (l *Logger) TraceF(format string, args ...any) {
+	l.Log(StrLevelTrace, fmt.Sprintf(format, args...))
+}
+
+// WarnF is a convenience method for log Warn using a formatted string
+func (l *Logger) WarnF(format string, args ...any) {
+	l.Log(StrLevelWarn, fmt.Sprintf(format, args...))
+}
+
+// ErrorF is a convenience method for log Error using a formatted string
+func (l *Logger) ErrorF(format string, args ...any) {
+	l.Log(StrLevelError, fmt.Sprintf(format, args...))
 }
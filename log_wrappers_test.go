@@ -1,6 +1,16 @@
 package logger
 
-import "testing"
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
 
 func TestFormattingFunctions(t *testing.T) {
 	InitLog(LogConfig{
@@ -45,43 +55,122 @@ func TestSimpleLogFunctions(t *testing.T) {
 	Error("Simple error message", "key1", "value1", "key2", "value2")
 }
 
-func TestStrArrayFromAnyArgs(t *testing.T) {
-	tests := []struct {
-		name     string
-		args     []interface{}
-		expected []string
-	}{
-		{
-			name:     "empty args",
-			args:     []interface{}{},
-			expected: []string{},
-		},
-		{
-			name:     "string arguments",
-			args:     []interface{}{"hello", "world"},
-			expected: []string{"hello", "world"},
-		},
-		{
-			name:     "mixed type arguments",
-			args:     []interface{}{123, "hello", 45.67, true, nil},
-			expected: []string{"123", "hello", "45.67", "true", "<nil>"},
-		},
+func TestTypedFieldValues(t *testing.T) {
+	lgr := New(LogConfig{Formatter: "json", LogLevel: "debug"})
+	defer lgr.Close()
+
+	var out bytes.Buffer
+	lgr.Logrus().SetOutput(&out)
+
+	callTime := time.Date(2024, 5, 11, 19, 30, 9, 0, time.UTC)
+	lgr.Warn("API response time is high",
+		"latency_ms", 1500,
+		"ratio", 0.75,
+		"cached", true,
+		"started", callTime,
+		"timeout", 2*time.Second,
+		"cause", errors.New("upstream slow"),
+		"tags", map[string]any{"region": "us-east", "shard": 3},
+	)
+
+	var entry map[string]any
+	if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+		t.Fatalf("unable to unmarshal log line %q: %v", out.String(), err)
 	}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			result := strArrayFromAnyArgs(tt.args...)
+	expected := map[string]any{
+		"latency_ms": float64(1500),
+		"ratio":      0.75,
+		"cached":     true,
+		"started":    "2024-05-11T19:30:09Z",
+		"timeout":    "2s",
+		"cause":      "upstream slow",
+	}
+	for key, val := range expected {
+		if entry[key] != val {
+			t.Errorf("field %q = %#v, expected %#v", key, entry[key], val)
+		}
+	}
 
-			if len(result) != len(tt.expected) {
-				t.Errorf("strArrayFromAnyArgs() length = %v, expected length %v", len(result), len(tt.expected))
-				return
-			}
+	tags, ok := entry["tags"].(map[string]any)
+	if !ok || tags["region"] != "us-east" || tags["shard"] != float64(3) {
+		t.Errorf("expected nested map in tags, got %#v", entry["tags"])
+	}
+}
+
+func TestUnmarshalableFieldValues(t *testing.T) {
+	lgr := New(LogConfig{Formatter: "json", LogLevel: "debug"})
+	defer lgr.Close()
 
-			for i, v := range result {
-				if v != tt.expected[i] {
-					t.Errorf("strArrayFromAnyArgs()[%d] = %v, expected %v", i, v, tt.expected[i])
-				}
+	var out bytes.Buffer
+	lgr.Logrus().SetOutput(&out)
+
+	lgr.Warn("Ratio out of range",
+		"ratio", math.NaN(),
+		"limit", math.Inf(1),
+		"floor", float32(math.Inf(-1)),
+		"handlers", map[string]any{"done": make(chan struct{})},
+	)
+
+	var entry map[string]any
+	if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+		t.Fatalf("expected the entry to be logged, got %q: %v", out.String(), err)
+	}
+	for key, val := range map[string]any{"ratio": "NaN", "limit": "+Inf", "floor": "-Inf"} {
+		if entry[key] != val {
+			t.Errorf("field %q = %#v, expected %#v", key, entry[key], val)
+		}
+	}
+	if s, ok := entry["handlers"].(string); !ok || !strings.HasPrefix(s, "map[done:0x") {
+		t.Errorf("expected the map with a chan to be stringified, got %#v", entry["handlers"])
+	}
+}
+
+func TestTraceAndPanicLevels(t *testing.T) {
+	logCh := make(chan string, 10)
+	lgr := New(LogConfig{
+		Formatter:  "json",
+		LogLevel:   "trace",
+		LogChanCfg: LogChanCfg{Enabled: true, Ch: logCh, LogLevel: "trace"},
+	})
+	defer lgr.Close()
+
+	var out bytes.Buffer
+	lgr.Logrus().SetOutput(&out)
+
+	lgr.Log("trace", "Entering handler")
+	lgr.Trace("Cache lookup", "key", "user:42")
+	lgr.TraceF("Retry %d of %d", 1, 3)
+	lgr.Log("bogus", "Should not vanish")
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected Panic to panic")
 			}
-		})
+		}()
+		lgr.Panic("Invariant violated", "state", "corrupt")
+	}()
+
+	for _, exp := range []string{
+		`"level":"trace","msg":"Entering handler"`,
+		`"level":"trace","msg":"Cache lookup"`,
+		`"level":"trace","msg":"Retry 1 of 3"`,
+		`Unknown level \"bogus\"`,
+		`"level":"info","msg":"Should not vanish"`,
+		`"level":"panic","msg":"Invariant violated"`,
+	} {
+		if !strings.Contains(out.String(), exp) {
+			t.Errorf("expected %s in output: %s", exp, out.String())
+		}
+	}
+
+	if n := len(logCh); n != 6 {
+		t.Errorf("expected the trace level LogChan hook to receive 6 messages, got %d", n)
+	}
+
+	lgr.SetLogLevel("trace")
+	if lvl := lgr.Logrus().GetLevel(); lvl != logrus.TraceLevel {
+		t.Errorf("expected trace level, got %s", lvl)
 	}
 }
@@ -0,0 +1,127 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Logger is an independent logging instance.
+// Each Logger carries its own logrus instance (level, formatter and hooks),
+// message prefix and async queue, so several subsystems in one binary
+// can be configured differently.
+// The package level functions (Log, LogErr, Info, LogAsync, etc.)
+// are thin wrappers over a default instance.
+type Logger struct {
+	*loggerCore               // state shared by a Logger and its children (see With)
+	fields      logrus.Fields // fields bound via With(), included in every entry
+	name        string        // hierarchical name (see Named), selects the level from ComponentLevels
+}
+
+// loggerCore is the state shared between a Logger and the children derived from it
+type loggerCore struct {
+	lr *logrus.Logger
+
+	mu     sync.RWMutex // guards prefix
+	prefix string
+
+	async  atomic.Pointer[asyncQueue] // queue for LogAsync, swapped by Reconfigure
+	levels atomic.Pointer[levelRules] // levels of named Loggers
+
+	cfgMu sync.Mutex    // serializes Reconfigure
+	cfg   LogConfig     // the config currently applied, with defaults filled in
+	hooks []managedHook // hooks built from cfg
+
+	savedOut io.Writer // the logrus output while Outputs replace it
+}
+
+// defaultLogger backs the package level functions.
+// It wraps the logrus standard logger so code logging through logrus directly
+// shares the same configuration.
+var defaultLogger = &Logger{loggerCore: &loggerCore{lr: logrus.StandardLogger()}}
+
+// New creates a Logger with its own logrus instance, configured from logCfg.
+// Call Close() on the returned Logger to flush async logs at shutdown.
+// Example:
+//
+//	billingLog := logger.New(logger.LogConfig{Formatter: "json", LogLevel: "warn"})
+//	defer billingLog.Close()
+//	billingLog.Warn("Invoice overdue", "invoice_id", "INV-1234")
+func New(logCfg LogConfig) *Logger {
+	l := &Logger{loggerCore: &loggerCore{lr: logrus.New()}}
+	l.Reconfigure(logCfg)
+	return l
+}
+
+// Default returns the Logger used by the package level functions
+func Default() *Logger {
+	return defaultLogger
+}
+
+// With returns a child of the default Logger which includes keyVals in every entry
+func With(keyVals ...any) *Logger {
+	return defaultLogger.With(keyVals...)
+}
+
+// With returns a child Logger which includes the key value pairs keyVals in every entry
+// (Info, Warn, Error, Err, Async, etc.), in addition to any fields bound on this Logger.
+// Children share the parent's configuration, hooks and async queue, and can be nested
+// without affecting the parent. Fields given at the log call take precedence.
+// Example:
+//
+//	billingLog := logger.With("service", "api", "component", "billing")
+//	billingLog.Info("Invoice created", "invoice_id", "INV-1234")
+//	billingLog.With("module", "tax").Warn("Unknown tax region", "region", "XX")
+func (l *Logger) With(keyVals ...any) *Logger {
+	flds := make(logrus.Fields, len(l.fields)+len(keyVals)/2)
+	for k, v := range l.fields {
+		flds[k] = v
+	}
+
+	key := ""
+	for i, kv := range keyVals {
+		if i%2 == 0 {
+			key = fmt.Sprintf("%v", kv)
+		} else {
+			flds[key] = fieldValue(kv)
+		}
+	}
+	if len(keyVals)%2 != 0 {
+		flds[key] = "" // a dangling key gets an empty value
+	}
+
+	return &Logger{loggerCore: l.loggerCore, fields: flds, name: l.name}
+}
+
+// Logrus returns the underlying logrus instance for this Logger
+func (l *Logger) Logrus() *logrus.Logger {
+	return l.lr
+}
+
+// Prefix returns the prefix currently prepended to log messages
+func (l *Logger) Prefix() string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.prefix
+}
+
+func (l *Logger) setPrefix(prefix string) {
+	l.mu.Lock()
+	l.prefix = prefix
+	l.mu.Unlock()
+}
+
+// boundKeyVals returns the fields bound via With() as key value pairs
+func (l *Logger) boundKeyVals() []any {
+	if len(l.fields) == 0 {
+		return nil
+	}
+	keyVals := make([]any, 0, len(l.fields)*2)
+	for k, v := range l.fields {
+		keyVals = append(keyVals, k, v)
+	}
+	return keyVals
+}
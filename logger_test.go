@@ -0,0 +1,105 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewInstancesAreIndependent(t *testing.T) {
+	billing := New(LogConfig{Formatter: "json", LogLevel: "warn", EnvPrefix: "[billing]"})
+	defer billing.Close()
+	search := New(LogConfig{Formatter: "text", LogLevel: "debug"})
+	defer search.Close()
+
+	var billingOut, searchOut bytes.Buffer
+	billing.Logrus().SetOutput(&billingOut)
+	search.Logrus().SetOutput(&searchOut)
+
+	billing.Info("Should be filtered out", "key1", "value1")
+	billing.Warn("Invoice overdue", "invoice_id", "INV-1234")
+	search.Debug("Query planned", "terms", "3")
+
+	if strings.Contains(billingOut.String(), "Should be filtered out") {
+		t.Errorf("billing logger should not log info messages: %s", billingOut.String())
+	}
+	if !strings.Contains(billingOut.String(), `"msg":"[billing] Invoice overdue"`) {
+		t.Errorf("billing logger should log prefixed JSON warnings, got: %s", billingOut.String())
+	}
+	if !strings.Contains(searchOut.String(), `msg="Query planned"`) {
+		t.Errorf("search logger should log text debug messages, got: %s", searchOut.String())
+	}
+	if strings.Contains(searchOut.String(), "Invoice overdue") {
+		t.Errorf("search logger received a message from the billing logger: %s", searchOut.String())
+	}
+}
+
+func TestInstanceLogErrAndAsync(t *testing.T) {
+	lgr := New(LogConfig{Formatter: "json", LogLevel: "debug"})
+
+	var out bytes.Buffer
+	lgr.Logrus().SetOutput(&out)
+
+	lgr.Err(errors.New("disk full"), "volume", "/data")
+	lgr.LogAsync("info", "An async instance message", "key1", "value1")
+	lgr.Close()
+
+	if !strings.Contains(out.String(), `logger_test.go:`) {
+		t.Errorf("expected caller location of the test in the error entry, got: %s", out.String())
+	}
+	if !strings.Contains(out.String(), `"volume":"/data"`) {
+		t.Errorf("expected error attributes in the output, got: %s", out.String())
+	}
+	if !strings.Contains(out.String(), "An async instance message") {
+		t.Errorf("expected async message to be flushed on Close, got: %s", out.String())
+	}
+}
+
+func TestWithBoundFields(t *testing.T) {
+	lgr := New(LogConfig{Formatter: "json", LogLevel: "debug"})
+
+	var out bytes.Buffer
+	lgr.Logrus().SetOutput(&out)
+
+	billing := lgr.With("service", "api", "component", "billing")
+	tax := billing.With("module", "tax", "component", "billing-tax")
+
+	billing.Info("Invoice created", "invoice_id", "INV-1234")
+	tax.Warn("Unknown tax region", "region", "XX")
+	billing.Err(errors.New("charge declined"))
+	billing.Err(errors.New("tax lookup failed"), "component", "billing-tax")
+	lgr.Info("Root entry")
+	tax.Async("info", "Tax tables reloaded")
+	lgr.Close()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) < 6 {
+		t.Fatalf("expected at least 6 log lines, got: %s", out.String())
+	}
+
+	checks := []struct {
+		line       string
+		expected   []string
+		unexpected []string
+	}{
+		{lines[0], []string{`"service":"api"`, `"component":"billing"`, `"invoice_id":"INV-1234"`}, []string{`"module"`}},
+		{lines[1], []string{`"service":"api"`, `"component":"billing-tax"`, `"module":"tax"`}, nil},
+		{lines[2], []string{`"service":"api"`, `"component":"billing"`, `"msg":"charge declined"`}, []string{`"module"`}},
+		{lines[3], []string{`"service":"api"`, `"component":"billing-tax"`}, []string{`"billing -`}},
+		{lines[4], []string{`"msg":"Root entry"`}, []string{`"service"`}},
+		{lines[5], []string{`"service":"api"`, `"module":"tax"`, `"msg":"Tax tables reloaded"`}, nil},
+	}
+	for i, chk := range checks {
+		for _, exp := range chk.expected {
+			if !strings.Contains(chk.line, exp) {
+				t.Errorf("line %d: expected %s in %s", i, exp, chk.line)
+			}
+		}
+		for _, unexp := range chk.unexpected {
+			if strings.Contains(chk.line, unexp) {
+				t.Errorf("line %d: did not expect %s in %s", i, unexp, chk.line)
+			}
+		}
+	}
+}
@@ -2,13 +2,16 @@ package slack_api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/rohanthewiz/logger/internal/hookctl"
 	"github.com/sirupsen/logrus"
 )
 
@@ -18,9 +21,19 @@ type SlackAPIHook struct {
 	Channel        string
 	AcceptedLevels []logrus.Level
 	Enabled        bool
-	UseBlocks      bool // Whether to use rich block formatting or simple messages
+	UseBlocks      bool   // Whether to use rich block formatting or simple messages
+	URL            string // Slack API endpoint of chat.postMessage; defaults to PostMessageURL
+
+	pending atomic.Int64    // messages being sent in the background
+	ctl     hookctl.Control // runtime changes of Enabled and AcceptedLevels
 }
 
+// PostMessageURL is the Slack Web API method messages are sent with
+const PostMessageURL = "https://slack.com/api/chat.postMessage"
+
+// flushPollInterval is how often Flush checks for pending messages
+const flushPollInterval = 10 * time.Millisecond
+
 // NewSlackAPIHook creates a new Slack API hook
 func NewSlackAPIHook(token, channel string, acceptedLevels []logrus.Level, useBlocks bool) *SlackAPIHook {
 	return &SlackAPIHook{
@@ -34,20 +47,39 @@ func NewSlackAPIHook(token, channel string, acceptedLevels []logrus.Level, useBl
 
 // Levels returns the log levels this hook should fire for
 func (h *SlackAPIHook) Levels() []logrus.Level {
-	if h.AcceptedLevels == nil {
+	levels := h.ctl.Levels(h.AcceptedLevels)
+	if levels == nil {
 		return logrus.AllLevels
 	}
-	return h.AcceptedLevels
+	return levels
+}
+
+// IsEnabled reports whether the hook fires
+func (h *SlackAPIHook) IsEnabled() bool {
+	return h.ctl.Enabled(h.Enabled)
+}
+
+// SetEnabled enables or disables the hook, safely while it fires
+func (h *SlackAPIHook) SetEnabled(enabled bool) {
+	h.ctl.SetEnabled(enabled)
+}
+
+// SetLevels changes the levels the hook accepts, safely while it fires
+func (h *SlackAPIHook) SetLevels(levels []logrus.Level) {
+	h.ctl.SetLevels(levels)
 }
 
 // Fire is called when a log event is fired
 func (h *SlackAPIHook) Fire(entry *logrus.Entry) error {
-	if !h.Enabled {
+	if !h.IsEnabled() {
 		return nil
 	}
 
 	// Send asynchronously to avoid blocking
+	h.pending.Add(1)
 	go func() {
+		defer h.pending.Add(-1)
+
 		var payload interface{}
 		if h.UseBlocks {
 			payload = h.createBlockMessage(entry)
@@ -64,6 +96,22 @@ func (h *SlackAPIHook) Fire(entry *logrus.Entry) error {
 	return nil
 }
 
+// Flush waits for messages being sent in the background to be delivered.
+// If ctx is done first, the returned error reports how many are still undelivered
+func (h *SlackAPIHook) Flush(ctx context.Context) error {
+	ticker := time.NewTicker(flushPollInterval)
+	defer ticker.Stop()
+
+	for h.pending.Load() > 0 {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("%d Slack messages undelivered: %w", h.pending.Load(), ctx.Err())
+		}
+	}
+	return nil
+}
+
 // createSimpleMessage creates a simple text message for Slack
 func (h *SlackAPIHook) createSimpleMessage(entry *logrus.Entry) map[string]interface{} {
 	// Format the basic message
@@ -233,7 +281,12 @@ func (h *SlackAPIHook) sendToSlack(payload interface{}) error {
 		return fmt.Errorf("failed to marshal payload: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", "https://slack.com/api/chat.postMessage", bytes.NewBuffer(jsonData))
+	url := h.URL
+	if url == "" {
+		url = PostMessageURL
+	}
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
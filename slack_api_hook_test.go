@@ -1,8 +1,17 @@
 package logger
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/rohanthewiz/logger/slack_api"
 	"github.com/rohanthewiz/serr"
 	"github.com/sirupsen/logrus"
 )
@@ -132,3 +141,40 @@ func ExampleInitLog_slackAPI() {
 	Info("Application started successfully")
 	Error("service", "api", "Database connection lost")
 }
+
+func TestSlackAPIHookFlush(t *testing.T) {
+	var received atomic.Int64
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		received.Add(1)
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+	defer srv.Close()
+
+	hook := slack_api.NewSlackAPIHook("xoxb-token", "C0123", logrus.AllLevels, true)
+	hook.URL = srv.URL
+
+	lgr := logrus.New()
+	lgr.SetOutput(&bytes.Buffer{})
+	lgr.AddHook(hook)
+	for range 5 {
+		lgr.Error("Payment gateway down")
+	}
+
+	// While Slack doesn't answer, Flush reports what is undelivered once ctx is done
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := hook.Flush(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "5 Slack messages undelivered") {
+		t.Errorf("expected the undelivered messages to be reported, got %v", err)
+	}
+
+	close(release)
+	if err := hook.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := received.Load(); n != 5 {
+		t.Errorf("expected 5 messages delivered after Flush, got %d", n)
+	}
+}
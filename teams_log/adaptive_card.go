@@ -0,0 +1,136 @@
+package teams_log
+
+import (
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const adaptiveCardContentType = "application/vnd.microsoft.card.adaptive"
+const adaptiveCardSchema = "http://adaptivecards.io/schemas/adaptive-card.json"
+const adaptiveCardVersion = "1.4"
+
+// containerStyles accents the card by level
+var containerStyles = map[logrus.Level]string{
+	logrus.TraceLevel: "default",
+	logrus.DebugLevel: "default",
+	logrus.InfoLevel:  "accent",
+	logrus.WarnLevel:  "warning",
+	logrus.ErrorLevel: "attention",
+	logrus.FatalLevel: "attention",
+	logrus.PanicLevel: "attention",
+}
+
+// titleColors colors the title by level
+var titleColors = map[logrus.Level]string{
+	logrus.TraceLevel: "Default",
+	logrus.DebugLevel: "Default",
+	logrus.InfoLevel:  "Accent",
+	logrus.WarnLevel:  "Warning",
+	logrus.ErrorLevel: "Attention",
+	logrus.FatalLevel: "Attention",
+	logrus.PanicLevel: "Attention",
+}
+
+// WorkflowMessage is the body a Teams Workflows (Power Automate) webhook expects
+type WorkflowMessage struct {
+	Type        string       `json:"type"` // hardwired to "message"
+	Attachments []Attachment `json:"attachments"`
+}
+
+type Attachment struct {
+	ContentType string       `json:"contentType"` // hardwired to "application/vnd.microsoft.card.adaptive"
+	ContentURL  *string      `json:"contentUrl"`  // always null for inline cards
+	Content     AdaptiveCard `json:"content"`
+}
+
+type AdaptiveCard struct {
+	Schema  string          `json:"$schema"`
+	Type    string          `json:"type"` // hardwired to "AdaptiveCard"
+	Version string          `json:"version"`
+	Body    []CardElement   `json:"body"`
+	Actions []OpenURLAction `json:"actions,omitempty"`
+	MSTeams *MSTeamsOptions `json:"msteams,omitempty"`
+}
+
+type MSTeamsOptions struct {
+	Width string `json:"width,omitempty"` // "Full" uses the whole width of the chat
+}
+
+// CardElement is one of the elements of a card we use: Container, TextBlock or FactSet
+type CardElement struct {
+	Type     string        `json:"type"`
+	Style    string        `json:"style,omitempty"` // Container: "default" | "accent" | "good" | "warning" | "attention"
+	Bleed    bool          `json:"bleed,omitempty"`
+	Items    []CardElement `json:"items,omitempty"`    // Container
+	Text     string        `json:"text,omitempty"`     // TextBlock
+	Weight   string        `json:"weight,omitempty"`   // TextBlock: "Default" | "Bolder"
+	Size     string        `json:"size,omitempty"`     // TextBlock: "Small" | "Default" | "Medium" | "Large"
+	Color    string        `json:"color,omitempty"`    // TextBlock: "Default" | "Accent" | "Warning" | "Attention"
+	FontType string        `json:"fontType,omitempty"` // TextBlock: "Default" | "Monospace"
+	IsSubtle bool          `json:"isSubtle,omitempty"`
+	Wrap     bool          `json:"wrap,omitempty"`
+	Spacing  string        `json:"spacing,omitempty"`
+	Facts    []CardFact    `json:"facts,omitempty"` // FactSet
+}
+
+type CardFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+type OpenURLAction struct {
+	Type  string `json:"type"` // hardwired to "Action.OpenUrl"
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// Action is a button opening URL shown on adaptive cards, e.g. a link to a dashboard or runbook
+type Action struct {
+	Title string
+	URL   string
+}
+
+// adaptiveCard renders the entry as an adaptive card wrapped in a Workflows message
+func (th *TeamsLogHook) adaptiveCard(le *logrus.Entry) WorkflowMessage {
+	c := th.content(le)
+
+	facts := make([]CardFact, 0, len(c.facts))
+	for _, f := range c.facts {
+		facts = append(facts, CardFact{Title: f.Name, Value: f.Value})
+	}
+
+	header := CardElement{
+		Type:  "Container",
+		Style: containerStyles[le.Level],
+		Bleed: true,
+		Items: []CardElement{
+			{Type: "TextBlock", Text: c.title, Weight: "Bolder", Size: "Medium", Color: titleColors[le.Level], Wrap: true},
+			{Type: "TextBlock", Text: strings.ToUpper(le.Level.String()) + " · " + le.Time.Format("2006-01-02 15:04:05 MST"),
+				IsSubtle: true, Spacing: "None", Wrap: true},
+		},
+	}
+
+	card := AdaptiveCard{
+		Schema:  adaptiveCardSchema,
+		Type:    "AdaptiveCard",
+		Version: adaptiveCardVersion,
+		Body:    []CardElement{header},
+		MSTeams: &MSTeamsOptions{Width: "Full"},
+	}
+
+	if len(facts) > 0 {
+		card.Body = append(card.Body, CardElement{Type: "FactSet", Facts: facts})
+	}
+	if c.errText != "" {
+		card.Body = append(card.Body, CardElement{Type: "TextBlock", Text: c.errText, FontType: "Monospace", Wrap: true})
+	}
+	for _, a := range th.Actions {
+		card.Actions = append(card.Actions, OpenURLAction{Type: "Action.OpenUrl", Title: a.Title, URL: a.URL})
+	}
+
+	return WorkflowMessage{
+		Type:        "message",
+		Attachments: []Attachment{{ContentType: adaptiveCardContentType, Content: card}},
+	}
+}
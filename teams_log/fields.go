@@ -0,0 +1,92 @@
+package teams_log
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+)
+
+// cardContent is what the cards show of an entry
+type cardContent struct {
+	title   string
+	errText string
+	facts   []Fact
+}
+
+// content splits the entry's fields into the title ("msg" overrides the message), the error
+// and facts. Facts are ordered by PinnedFields, then by name; ExcludedFields are left out
+func (th *TeamsLogHook) content(le *logrus.Entry) cardContent {
+	c := cardContent{title: le.Message}
+
+	names := make([]string, 0, len(le.Data))
+	for k := range le.Data {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	sort.SliceStable(names, func(i, j int) bool { return th.pinRank(names[i]) < th.pinRank(names[j]) })
+
+	for _, k := range names {
+		if slices.Contains(th.ExcludedFields, k) {
+			continue
+		}
+		val := FormatValue(le.Data[k])
+
+		switch k {
+		case "msg":
+			c.title = val
+		case "error":
+			c.errText = val
+		default:
+			c.facts = append(c.facts, Fact{Name: k, Value: val})
+		}
+	}
+	return c
+}
+
+// pinRank orders pinned fields by their position in PinnedFields, ahead of the others
+func (th *TeamsLogHook) pinRank(name string) int {
+	if i := slices.Index(th.PinnedFields, name); i >= 0 {
+		return i
+	}
+	return len(th.PinnedFields)
+}
+
+// FormatValue renders a field value for a card: errors and Stringers (e.g. time.Duration) as text,
+// maps, slices and structs as JSON, and anything else as fmt prints it
+func FormatValue(v any) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return val
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return "<nil>" // the Error or String method of a nil pointer may dereference it
+	}
+
+	switch val := v.(type) {
+	case error:
+		return val.Error()
+	case fmt.Stringer:
+		return val.String()
+	}
+
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		if b, err := json.Marshal(v); err == nil {
+			return string(b)
+		}
+		return fmt.Sprintf("%+v", v)
+	default:
+		return fmt.Sprint(rv.Interface())
+	}
+}
@@ -1,13 +1,30 @@
 package teams_log
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"sync"
+	"time"
 
+	"github.com/rohanthewiz/logger/internal/batch"
+	"github.com/rohanthewiz/logger/internal/hookctl"
 	"github.com/rohanthewiz/serr"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	DefaultTimeout    = 10 * time.Second
+	defaultMaxRetries = 5
+	defaultMinBackoff = time.Second
+	defaultMaxBackoff = time.Minute
+	closeTimeout      = 5 * time.Second
+)
+
 var logIcons = map[logrus.Level]string{
+	logrus.TraceLevel: "https://d2kk8pyj1kjlmo.cloudfront.net/icons/notepad_32.png",
 	logrus.DebugLevel: "https://d2kk8pyj1kjlmo.cloudfront.net/icons/notepad_32.png",
 	logrus.InfoLevel:  "https://d2kk8pyj1kjlmo.cloudfront.net/icons/note_32.png",
 	logrus.WarnLevel:  "https://d2kk8pyj1kjlmo.cloudfront.net/icons/flash_32.png",
@@ -16,13 +33,44 @@ var logIcons = map[logrus.Level]string{
 	logrus.PanicLevel: "https://d2kk8pyj1kjlmo.cloudfront.net/icons/dead_scrn_32.png",
 }
 
+// Card formats of TeamsLogHook
+const (
+	CardFormatMessageCard  = "message_card"  // legacy Office 365 connector card
+	CardFormatAdaptiveCard = "adaptive_card" // Adaptive Card for Workflows (Power Automate) webhooks
+)
+
+// TeamsLogHook posts log entries to a Teams webhook as cards.
+// Cards are queued and posted in the background, so a slow endpoint doesn't stall logging.
+// Failed posts are retried with exponential backoff, waiting as long as Teams asks on 429
 type TeamsLogHook struct {
 	AcceptedLevels []logrus.Level
 	URL            string
 	Disabled       bool
+	CardFormat     string        // CardFormatMessageCard (default) or CardFormatAdaptiveCard
+	Actions        []Action      // buttons of adaptive cards
+	PinnedFields   []string      // fields shown first, in this order; the others follow sorted by name
+	ExcludedFields []string      // fields left off the cards
+	Client         *http.Client  // if nil, a client with DefaultTimeout is used
+	Backoff        batch.Backoff // retries of failed posts
+
+	ctl       hookctl.Control // runtime changes of Disabled and AcceptedLevels
+	queueOnce sync.Once
+	queue     *batch.Batcher[[]byte] // created with the first card
+}
+
+// NewTeamsLogHook creates a TeamsLogHook which retries failed posts.
+// Call Close to send remaining cards when done
+func NewTeamsLogHook(url string, acceptedLevels []logrus.Level) *TeamsLogHook {
+	return &TeamsLogHook{
+		URL:            url,
+		AcceptedLevels: acceptedLevels,
+		Client:         &http.Client{Timeout: DefaultTimeout},
+		Backoff:        batch.Backoff{MaxRetries: defaultMaxRetries, Min: defaultMinBackoff, Max: defaultMaxBackoff},
+	}
 }
 
 var allLevels = []logrus.Level{
+	logrus.TraceLevel,
 	logrus.DebugLevel,
 	logrus.InfoLevel,
 	logrus.WarnLevel,
@@ -34,10 +82,26 @@ var allLevels = []logrus.Level{
 // Levels sets which levels to send to Teams
 // This method is required for logrus hooks
 func (th *TeamsLogHook) Levels() []logrus.Level {
-	if th.AcceptedLevels == nil {
+	levels := th.ctl.Levels(th.AcceptedLevels)
+	if levels == nil {
 		return allLevels
 	}
-	return th.AcceptedLevels
+	return levels
+}
+
+// IsEnabled reports whether the hook fires
+func (th *TeamsLogHook) IsEnabled() bool {
+	return th.ctl.Enabled(!th.Disabled)
+}
+
+// SetEnabled enables or disables the hook, safely while it fires
+func (th *TeamsLogHook) SetEnabled(enabled bool) {
+	th.ctl.SetEnabled(enabled)
+}
+
+// SetLevels changes the levels the hook accepts, safely while it fires
+func (th *TeamsLogHook) SetLevels(levels []logrus.Level) {
+	th.ctl.SetLevels(levels)
 }
 
 // levelThreshold - Returns every logging level above and including the given parameter.
@@ -50,51 +114,100 @@ func AllowedLevels(lvl logrus.Level) []logrus.Level {
 	return []logrus.Level{}
 }
 
-func (th TeamsLogHook) Fire(le *logrus.Entry) (err error) {
-	if th.Disabled {
+// Fire queues the entry's card to be posted.
+// This method is required for logrus hooks
+func (th *TeamsLogHook) Fire(le *logrus.Entry) (err error) {
+	if !th.IsEnabled() {
+		return nil
+	}
+
+	var msg any
+	if th.CardFormat == CardFormatAdaptiveCard {
+		msg = th.adaptiveCard(le)
+	} else {
+		msg = th.messageCard(le)
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		fmt.Println("teams_log: unable to marshal message card:", err)
 		return nil
 	}
 
+	if !th.batch().Add(body) {
+		fmt.Println("teams_log: queue full, dropping log entry")
+	}
+	return nil
+}
+
+// Flush posts queued cards, waiting for them to be delivered or ctx to be done
+func (th *TeamsLogHook) Flush(ctx context.Context) error {
+	return th.batch().Flush(ctx)
+}
+
+// Close posts queued cards and stops the sender
+func (th *TeamsLogHook) Close() error {
+	return th.batch().Close(closeTimeout)
+}
+
+// batch returns the queue of cards, posted one at a time
+func (th *TeamsLogHook) batch() *batch.Batcher[[]byte] {
+	th.queueOnce.Do(func() { th.queue = batch.New(1, time.Second, th.post) })
+	return th.queue
+}
+
+// post sends each card, retrying with backoff
+func (th *TeamsLogHook) post(cards [][]byte) {
+	client := th.Client
+	if client == nil {
+		client = defaultClient
+	}
+
+	backoff := th.Backoff
+	if backoff == (batch.Backoff{}) { // e.g. a TeamsLogHook{} built without NewTeamsLogHook
+		backoff = batch.Backoff{MaxRetries: defaultMaxRetries, Min: defaultMinBackoff, Max: defaultMaxBackoff}
+	}
+
+	for i, body := range cards {
+		err := backoff.Retry(th.batch(), func() (time.Duration, error) {
+			return postCard(client, th.URL, body)
+		})
+		if errors.Is(err, batch.ErrAbandoned) {
+			fmt.Printf("teams_log: dropping %d cards: %v\n", len(cards)-i, err)
+			return
+		}
+		if err != nil {
+			ser, ok := err.(serr.SErr)
+			if ok {
+				fmt.Println(ser.String())
+			} else {
+				fmt.Println(err)
+			}
+		}
+	}
+}
+
+// messageCard renders the entry as a legacy connector MessageCard
+func (th *TeamsLogHook) messageCard(le *logrus.Entry) MessageCard {
 	mc := MessageCard{
 		Type:    messageCardType,
 		Context: messageCardContext,
 		Summary: "Log",
 	}
 
+	c := th.content(le)
 	sec := Section{
-		ActivityTitle: le.Message,
+		ActivityTitle: c.title,
 		// ActivitySubtitle: // le.Time.Format("2006-01-02 15:04 MST"),
 		ActivityImage: logIcons[le.Level],
 	}
-
-	for k, v := range le.Data {
-		val, ok := v.(string)
-		if !ok {
-			continue
-		}
-
-		switch k {
-		case "msg":
-			sec.ActivityTitle = val
-		case "error":
-			sec.ActivityText = "`" + val + "`" // quiet markdown formatting
-
-		default:
-			sec.Facts = append(sec.Facts, Fact{Name: k, Value: "`" + val + "`"})
-		}
+	if c.errText != "" {
+		sec.ActivityText = "`" + c.errText + "`" // quiet markdown formatting
 	}
-
-	mc.Sections = []Section{sec}
-
-	err = SendLog(mc, th.URL)
-	if err != nil {
-		ser, ok := err.(serr.SErr)
-		if ok {
-			fmt.Println(ser.String())
-		} else {
-			fmt.Println(err)
-		}
+	for _, f := range c.facts {
+		sec.Facts = append(sec.Facts, Fact{Name: f.Name, Value: "`" + f.Value + "`"})
 	}
 
-	return
+	mc.Sections = []Section{sec}
+	return mc
 }
@@ -6,31 +6,53 @@ import (
 	"io"
 	"net/http"
 	"strconv"
+	"time"
 
+	"github.com/rohanthewiz/logger/internal/batch"
 	"github.com/rohanthewiz/serr"
 )
 
-func SendLog(msg MessageCard, url string) (err error) {
+// defaultClient posts cards when no client is given, so a hung endpoint can't block forever
+var defaultClient = &http.Client{Timeout: DefaultTimeout}
+
+// SendLog posts a MessageCard or WorkflowMessage to the Teams webhook, without retrying
+func SendLog(msg any, url string) (err error) {
 	msgBytes, err := json.Marshal(msg)
 	if err != nil {
 		return serr.Wrap(err, "Unable to marshal message card")
 	}
 
-	resp, err := http.Post(url, "application/json", bytes.NewReader(msgBytes))
+	_, err = postCard(defaultClient, url, msgBytes)
+	return
+}
+
+// postCard posts the marshalled card. A negative retryAfter means the post must not be retried
+func postCard(client *http.Client, url string, body []byte) (retryAfter time.Duration, err error) {
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
 	if err != nil {
-		return serr.Wrap(err, "Post to Teams connector failed")
+		return 0, serr.Wrap(err, "Post to Teams connector failed")
 	}
 	defer func() {
 		_ = resp.Body.Close()
 	}()
 
-	if resp.StatusCode != 200 {
-		rb, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return serr.Wrap(err, "when", "error marshalling response body", "code", strconv.Itoa(resp.StatusCode))
-		}
-		return serr.New("Non-200 response code", "code", strconv.Itoa(resp.StatusCode), "body", string(rb))
+	// Workflows webhooks answer 202 Accepted
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return 0, nil
 	}
 
-	return
+	rb, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return 0, serr.Wrap(err, "when", "error marshalling response body", "code", strconv.Itoa(resp.StatusCode))
+	}
+	err = serr.New("Non-2xx response code", "code", strconv.Itoa(resp.StatusCode), "body", string(rb))
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return batch.RetryAfter(resp.Header.Get("Retry-After")), err
+	case resp.StatusCode >= 500:
+		return 0, err
+	default:
+		return -1, err
+	}
 }